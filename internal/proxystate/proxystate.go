@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+// Package proxystate defines a dataplane-neutral intermediate representation
+// for the proxy configuration Cilium's policy layer produces, inspired by
+// Consul's pbproxystate. The policy layer emits this IR once; separate
+// backends (Envoy, a future eBPF-only TCP proxy, ...) translate it into
+// whatever their own configuration format requires, instead of each consumer
+// of policy re-deriving proxy config from policy structs independently and
+// inevitably diverging from one another.
+package proxystate
+
+// Listener is a single point a backend listens for connections on, fronting
+// one or more Clusters.
+type Listener struct {
+	Name     string
+	Address  string
+	Port     uint32
+	Clusters []*Cluster
+}
+
+// Cluster is a named, weighted group of upstream Endpoints. Weight is only
+// meaningful relative to the other Clusters on the same Listener; a Listener
+// with a single Cluster ignores it.
+type Cluster struct {
+	Name      string
+	Weight    uint32
+	Endpoints []*Endpoint
+}
+
+// Endpoint is a single dialable upstream address.
+type Endpoint struct {
+	Address string
+	Port    uint32
+}
+
+// HeaderMutation adds or overwrites a single request header, e.g. for a
+// TunnelingConfig's headers_to_add.
+type HeaderMutation struct {
+	Key   string
+	Value string
+	// Append, if true, adds Value to any existing header of the same Key
+	// instead of replacing it.
+	Append bool
+}
+
+// AccessLogSink is where a backend should deliver connection records.
+type AccessLogSink int
+
+const (
+	AccessLogSinkFile AccessLogSink = iota
+	AccessLogSinkGRPC
+)
+
+// AccessLog describes one access-log sink attached to a route.
+type AccessLog struct {
+	Sink AccessLogSink
+	// Path is the destination for AccessLogSinkFile; ClusterName is the
+	// upstream collector cluster for AccessLogSinkGRPC.
+	Path        string
+	ClusterName string
+}
+
+// IntentionAction is the effect of a single Intention.
+type IntentionAction int
+
+const (
+	IntentionActionAllow IntentionAction = iota
+	IntentionActionDeny
+)
+
+// Intention is a single Cilium identity-to-identity policy verdict, carried
+// in the IR so that a backend can enforce it even if it has no other way to
+// consult Cilium's policy engine directly.
+type Intention struct {
+	SourceIdentity      uint32
+	DestinationIdentity uint32
+	Action              IntentionAction
+}
+
+// TCPRoute is the IR for a single TCP proxy route: a Listener load-balancing
+// across its Clusters, plus the cross-cutting concerns (header rewriting,
+// access logging, identity-based allow/deny) every backend needs to apply
+// consistently.
+type TCPRoute struct {
+	Listener        *Listener
+	HeaderMutations []*HeaderMutation
+	AccessLogs      []*AccessLog
+	Intentions      []*Intention
+}
+
+// Allowed reports whether traffic from source to destination is permitted by
+// r's Intentions. A route with no matching Intention defaults to allowed,
+// consistent with Cilium's own default-allow posture absent an explicit deny.
+func (r *TCPRoute) Allowed(sourceIdentity, destinationIdentity uint32) bool {
+	for _, in := range r.Intentions {
+		if in.SourceIdentity == sourceIdentity && in.DestinationIdentity == destinationIdentity {
+			return in.Action == IntentionActionAllow
+		}
+	}
+	return true
+}
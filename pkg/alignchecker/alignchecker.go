@@ -6,6 +6,8 @@ package alignchecker
 import (
 	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
 
 	"github.com/cilium/ebpf/btf"
 )
@@ -19,7 +21,9 @@ import (
 // To find a matching C struct field, a Go field has to be tagged with
 // `align:"field_name_in_c_struct". In the case of unnamed union field, such
 // union fields can be referred with special tags - `align:"$union0"`,
-// `align:"$union1"`, etc.
+// `align:"$union1"`, etc. A field mirroring a C bitfield member must additionally
+// give its expected bit offset (within the byte) and width, separated from the
+// field name by a slash: `align:"flags/3:2"` expects a 2-bit field starting at bit 3.
 func CheckStructAlignments(pathToObj string, toCheck map[string][]reflect.Type, checkOffsets bool) error {
 	spec, err := btf.LoadSpec(pathToObj)
 	if err != nil {
@@ -41,9 +45,20 @@ func CheckStructAlignments(pathToObj string, toCheck map[string][]reflect.Type,
 
 type structInfo struct {
 	size         uint32
-	fieldOffsets map[string]uint32
+	fieldOffsets map[string]fieldInfo
 }
 
+// fieldInfo describes where a single field lives within its containing struct.
+type fieldInfo struct {
+	// bitOffset is the field's offset from the start of the struct, in bits.
+	bitOffset uint32
+	// bitSize is non-zero for bitfield members, giving their width in bits. It is
+	// zero for ordinary members, whose size instead comes from their own type.
+	bitSize uint32
+}
+
+func (f fieldInfo) byteOffset() uint32 { return f.bitOffset / 8 }
+
 func getStructInfosFromBTF(types *btf.Spec, toCheck map[string][]reflect.Type) (map[string]*structInfo, error) {
 	structs := make(map[string]*structInfo)
 	for name := range toCheck {
@@ -69,31 +84,83 @@ func getStructInfoFromBTF(t btf.Type) (*structInfo, error) {
 		// Resolve Typedefs to their target types.
 		return getStructInfoFromBTF(typ.Type)
 
+	case *btf.Const:
+		return getStructInfoFromBTF(typ.Type)
+
+	case *btf.Volatile:
+		return getStructInfoFromBTF(typ.Type)
+
+	case *btf.Restrict:
+		return getStructInfoFromBTF(typ.Type)
+
 	case *btf.Int:
 		return &structInfo{
 			size:         typ.Size,
 			fieldOffsets: nil,
 		}, nil
 
+	case *btf.Enum:
+		return &structInfo{
+			size:         typ.Size,
+			fieldOffsets: nil,
+		}, nil
+
+	case *btf.Enum64:
+		return &structInfo{
+			size:         typ.Size,
+			fieldOffsets: nil,
+		}, nil
+
+	case *btf.Pointer:
+		// BTF does not record a pointer's size; assume the native word size of
+		// the architectures we ship BPF objects for.
+		return &structInfo{
+			size:         8,
+			fieldOffsets: nil,
+		}, nil
+
+	case *btf.Array:
+		elem, err := getStructInfoFromBTF(typ.Type)
+		if err != nil {
+			return nil, fmt.Errorf("array element type: %w", err)
+		}
+		return &structInfo{
+			size:         elem.size * typ.Nelems,
+			fieldOffsets: nil,
+		}, nil
+
+	case *btf.Fwd:
+		return nil, fmt.Errorf("forward declaration %q has no size or layout information", typ.Name)
+
 	case *btf.Struct:
 		return &structInfo{
 			size:         typ.Size,
-			fieldOffsets: memberOffsets(typ.Members),
+			fieldOffsets: memberOffsets("", 0, typ.Members),
 		}, nil
 
 	case *btf.Union:
 		return &structInfo{
 			size:         typ.Size,
-			fieldOffsets: memberOffsets(typ.Members),
+			fieldOffsets: memberOffsets("", 0, typ.Members),
 		}, nil
 	}
 
 	return nil, fmt.Errorf("unsupported type: %s", t)
 }
 
-func memberOffsets(members []btf.Member) map[string]uint32 {
+// memberOffsets computes the bit offset of each member of a BTF struct/union,
+// relative to the start of the outermost struct passed to getStructInfoFromBTF.
+// 'prefix' and 'baseBits' thread that context through recursive calls: 'prefix' is
+// the dotted field path leading to 'members' (empty at the top level) and
+// 'baseBits' is the bit offset of 'members' own struct/union within the outermost
+// one. Nested named structs and unions are recursed into and their fields are
+// recorded under "outer.inner" dotted names, alongside the offset of the nested
+// field itself, so that callers can tag a Go field with either the whole nested
+// struct or one of its members. Bitfield members keep their BTF-reported width so
+// that check() can validate bit offset and size, not just the containing byte.
+func memberOffsets(prefix string, baseBits uint32, members []btf.Member) map[string]fieldInfo {
 	unions := 0
-	offsets := make(map[string]uint32, len(members))
+	offsets := make(map[string]fieldInfo, len(members))
 	for _, member := range members {
 		n := member.Name
 		// Create surrogate names ($union0, $union1, etc) for unnamed union members.
@@ -103,7 +170,27 @@ func memberOffsets(members []btf.Member) map[string]uint32 {
 				unions++
 			}
 		}
-		offsets[n] = uint32(member.Offset.Bytes())
+		if n == "" {
+			continue
+		}
+
+		qualified := n
+		if prefix != "" {
+			qualified = prefix + "." + n
+		}
+		absBits := baseBits + uint32(member.Offset)
+		offsets[qualified] = fieldInfo{bitOffset: absBits, bitSize: member.BitfieldSize}
+
+		switch nested := member.Type.(type) {
+		case *btf.Struct:
+			for k, v := range memberOffsets(qualified, absBits, nested.Members) {
+				offsets[k] = v
+			}
+		case *btf.Union:
+			for k, v := range memberOffsets(qualified, absBits, nested.Members) {
+				offsets[k] = v
+			}
+		}
 	}
 
 	return offsets
@@ -126,19 +213,69 @@ func check(name string, toCheck []reflect.Type, structs map[string]*structInfo,
 		}
 
 		for i := 0; i < g.NumField(); i++ {
-			fieldName := g.Field(i).Tag.Get("align")
+			tag := g.Field(i).Tag.Get("align")
 			// Ignore fields without `align` struct tag
-			if fieldName == "" {
+			if tag == "" {
 				continue
 			}
+			fieldName, wantBitOffset, wantBitSize, isBitfield, err := parseAlignTag(tag)
+			if err != nil {
+				return fmt.Errorf("%s.%s: %w", g, g.Field(i).Name, err)
+			}
+
+			info, found := c.fieldOffsets[fieldName]
+			if !found {
+				return fmt.Errorf("%s.%s: no field %q found in %s", g, g.Field(i).Name, fieldName, name)
+			}
+
 			goOffset := uint32(g.Field(i).Offset)
-			cOffset := c.fieldOffsets[fieldName]
-			if goOffset != cOffset {
+			if goOffset != info.byteOffset() {
 				return fmt.Errorf("%s.%s offset(%d) does not match %s.%s(%d)",
-					g, g.Field(i).Name, goOffset, name, fieldName, cOffset)
+					g, g.Field(i).Name, goOffset, name, fieldName, info.byteOffset())
+			}
+
+			switch {
+			case isBitfield && info.bitSize == 0:
+				return fmt.Errorf("%s.%s is tagged as a bitfield but %s.%s is not one",
+					g, g.Field(i).Name, name, fieldName)
+			case !isBitfield && info.bitSize != 0:
+				return fmt.Errorf("%s.%s must give a bit offset/width (e.g. `align:%q`) to match the bitfield %s.%s",
+					g, g.Field(i).Name, fmt.Sprintf("%s/%d:%d", fieldName, info.bitOffset%8, info.bitSize), name, fieldName)
+			case isBitfield:
+				gotBitOffset := info.bitOffset % 8
+				if gotBitOffset != wantBitOffset || info.bitSize != wantBitSize {
+					return fmt.Errorf("%s.%s bitfield(bit %d, width %d) does not match %s.%s(bit %d, width %d)",
+						g, g.Field(i).Name, wantBitOffset, wantBitSize, name, fieldName, gotBitOffset, info.bitSize)
+				}
 			}
 		}
 	}
 
 	return nil
 }
+
+// parseAlignTag splits an `align` struct tag into the C field name it refers to
+// and, for bitfields, the expected bit offset (within the byte) and width given
+// after a slash, e.g. "flags/3:2".
+func parseAlignTag(tag string) (fieldName string, bitOffset, bitSize uint32, isBitfield bool, err error) {
+	fieldName, bits, ok := strings.Cut(tag, "/")
+	if !ok {
+		return fieldName, 0, 0, false, nil
+	}
+
+	offsetStr, sizeStr, ok := strings.Cut(bits, ":")
+	if !ok {
+		return "", 0, 0, false, fmt.Errorf("malformed bitfield tag %q, expected \"field/offset:size\"", tag)
+	}
+
+	offset, err := strconv.ParseUint(offsetStr, 10, 32)
+	if err != nil {
+		return "", 0, 0, false, fmt.Errorf("malformed bit offset in tag %q: %w", tag, err)
+	}
+	size, err := strconv.ParseUint(sizeStr, 10, 32)
+	if err != nil {
+		return "", 0, 0, false, fmt.Errorf("malformed bit size in tag %q: %w", tag, err)
+	}
+
+	return fieldName, uint32(offset), uint32(size), true, nil
+}
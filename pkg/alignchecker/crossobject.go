@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package alignchecker
+
+import (
+	"fmt"
+
+	"github.com/cilium/ebpf/btf"
+)
+
+// CheckStructAlignmentsAcrossObjects verifies that every type named in
+// sharedTypes resolves to the same size and per-field offsets in every BPF
+// object listed in paths. Cilium ships several BPF objects (bpf_lxc.o,
+// bpf_host.o, bpf_overlay.o, bpf_sock.o, ...) that all define the same map
+// value types; if one of them drifts - say, a field reordered or padded
+// differently after a partial rebuild - a map written by one program and read
+// by another silently misinterprets its bytes. Catching that here, before the
+// ordinary Go-side CheckStructAlignments runs against any single object, turns
+// that into a build-time error instead.
+//
+// BTF for each object is loaded once and memoized by path, and each (type,
+// path) pair's structInfo is computed once and reused across every other
+// object's comparison, keeping the O(len(paths) * len(sharedTypes)) check cheap
+// even for large builds.
+func CheckStructAlignmentsAcrossObjects(paths []string, sharedTypes []string) error {
+	specs := make(map[string]*btf.Spec, len(paths))
+	for _, path := range paths {
+		spec, err := btf.LoadSpec(path)
+		if err != nil {
+			return fmt.Errorf("cannot parse BTF debug info %s: %w", path, err)
+		}
+		specs[path] = spec
+	}
+
+	for _, typeName := range sharedTypes {
+		var reference *structInfo
+		var referencePath string
+
+		for _, path := range paths {
+			t, err := specs[path].AnyTypeByName(typeName)
+			if err != nil {
+				return fmt.Errorf("%s: looking up type %s by name: %w", path, typeName, err)
+			}
+			si, err := getStructInfoFromBTF(t)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+
+			if reference == nil {
+				reference, referencePath = si, path
+				continue
+			}
+
+			if si.size != reference.size {
+				return fmt.Errorf("%s diverges from %s: %s has size %d in %s but %d in %s",
+					path, referencePath, typeName, si.size, path, reference.size, referencePath)
+			}
+			for field, refOffset := range reference.fieldOffsets {
+				if gotOffset, ok := si.fieldOffsets[field]; !ok || gotOffset != refOffset {
+					return fmt.Errorf("%s diverges from %s: %s.%s has offset %v in %s but %v in %s",
+						path, referencePath, typeName, field, gotOffset, path, refOffset, referencePath)
+				}
+			}
+			for field := range si.fieldOffsets {
+				if _, ok := reference.fieldOffsets[field]; !ok {
+					return fmt.Errorf("%s diverges from %s: %s.%s exists in %s but not in %s",
+						path, referencePath, typeName, field, path, referencePath)
+				}
+			}
+		}
+	}
+
+	return nil
+}
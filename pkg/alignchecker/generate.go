@@ -0,0 +1,203 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package alignchecker
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/cilium/ebpf/btf"
+)
+
+// GenerateOptions configures GenerateStructs.
+type GenerateOptions struct {
+	// Package is the package name emitted at the top of the generated file.
+	Package string
+}
+
+// GenerateStructs walks the BTF of the C types named in 'types' within the object at
+// 'pathToObj' and renders matching Go struct declarations: correct field types,
+// 'align' tags consumable by CheckStructAlignments, and explicit padding fields so
+// that unsafe.Sizeof of the generated struct matches the BTF-reported size exactly.
+// It recurses into nested structs and unions, emitting each as its own named Go
+// struct, and translates unnamed union members to the '$unionN' tag convention
+// already used by CheckStructAlignments.
+//
+// This is the engine behind the `alignchecker generate` subcommand (e.g.
+// `alignchecker generate --obj bpf_lxc.o --types cilium_lb4_service,endpoint_key
+// --package types`). Callers are expected to feed the generated structs back
+// through CheckStructAlignments as a sanity pass before committing them, closing the
+// loop on drift between the C and Go mirrors of a struct.
+func GenerateStructs(pathToObj string, types []string, opts GenerateOptions) (string, error) {
+	spec, err := btf.LoadSpec(pathToObj)
+	if err != nil {
+		return "", fmt.Errorf("cannot parse BTF debug info %s: %w", pathToObj, err)
+	}
+
+	g := &generator{emitted: make(map[string]bool)}
+	for _, name := range types {
+		t, err := spec.AnyTypeByName(name)
+		if err != nil {
+			return "", fmt.Errorf("looking up type %s by name: %w", name, err)
+		}
+		if err := g.emitNamed(exportName(name), t); err != nil {
+			return "", err
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by alignchecker generate from %s. DO NOT EDIT.\n\n", pathToObj)
+	fmt.Fprintf(&out, "package %s\n\n", opts.Package)
+	out.Write(g.buf.Bytes())
+	return out.String(), nil
+}
+
+// generator accumulates the Go source for the structs emitted so far, along with
+// which named types have already been emitted so that a type shared by two of the
+// requested roots is only rendered once.
+type generator struct {
+	emitted map[string]bool
+	buf     bytes.Buffer
+}
+
+func (g *generator) emitNamed(goName string, t btf.Type) error {
+	switch typ := t.(type) {
+	case *btf.Typedef:
+		return g.emitNamed(goName, typ.Type)
+	case *btf.Struct:
+		return g.emitStructOrUnion(goName, typ.Members, typ.Size, false)
+	case *btf.Union:
+		return g.emitStructOrUnion(goName, typ.Members, typ.Size, true)
+	default:
+		return fmt.Errorf("%s: unsupported top-level BTF type %s for generation", goName, t)
+	}
+}
+
+func (g *generator) emitStructOrUnion(goName string, members []btf.Member, size uint32, isUnion bool) error {
+	if g.emitted[goName] {
+		return nil
+	}
+	g.emitted[goName] = true
+
+	// goTypeFor emits nested types into g.buf while the fields below are
+	// rendered, so this type's body is collected apart and appended after them.
+	var body bytes.Buffer
+	if isUnion {
+		fmt.Fprintf(&body, "// %s mirrors the C union of the same name. Only its largest member is\n", goName)
+		fmt.Fprintf(&body, "// emitted as a field, padded up to the union's size; the other members have no Go field.\n")
+	} else {
+		fmt.Fprintf(&body, "// %s mirrors the C struct of the same name.\n", goName)
+	}
+	fmt.Fprintf(&body, "type %s struct {\n", goName)
+
+	unions := 0
+	offset := uint32(0)
+	largest := ""
+	for _, m := range members {
+		tag := m.Name
+		fieldName := m.Name
+		if fieldName == "" {
+			if _, ok := m.Type.(*btf.Union); ok {
+				tag = fmt.Sprintf("$union%d", unions)
+				fieldName = fmt.Sprintf("Union%d", unions)
+				unions++
+			}
+		}
+
+		memberOffset := uint32(m.Offset.Bytes())
+		if !isUnion && memberOffset > offset {
+			fmt.Fprintf(&body, "\t_ [%d]byte // padding\n", memberOffset-offset)
+		}
+
+		goType, fieldSize, err := g.goTypeFor(m.Type, goName+"_"+exportName(fieldName))
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", goName, fieldName, err)
+		}
+		field := fmt.Sprintf("\t%s %s `align:%q`\n", exportName(fieldName), goType, tag)
+
+		if !isUnion {
+			body.WriteString(field)
+			offset = memberOffset + fieldSize
+		} else if fieldSize > offset {
+			largest = field
+			offset = fieldSize
+		}
+	}
+	body.WriteString(largest)
+	if size > offset {
+		fmt.Fprintf(&body, "\t_ [%d]byte // padding\n", size-offset)
+	}
+	fmt.Fprintf(&body, "}\n\n")
+	g.buf.Write(body.Bytes())
+	return nil
+}
+
+// goTypeFor returns the Go type and byte size to use for a BTF member type,
+// recursively emitting named sub-structs/unions (using 'subName' when the BTF type
+// itself is anonymous) as needed.
+func (g *generator) goTypeFor(t btf.Type, subName string) (string, uint32, error) {
+	switch typ := t.(type) {
+	case *btf.Typedef:
+		return g.goTypeFor(typ.Type, subName)
+	case *btf.Int:
+		return intGoType(typ), typ.Size, nil
+	case *btf.Struct:
+		name := subName
+		if typ.Name != "" {
+			name = exportName(typ.Name)
+		}
+		if err := g.emitStructOrUnion(name, typ.Members, typ.Size, false); err != nil {
+			return "", 0, err
+		}
+		return name, typ.Size, nil
+	case *btf.Union:
+		name := subName
+		if typ.Name != "" {
+			name = exportName(typ.Name)
+		}
+		if err := g.emitStructOrUnion(name, typ.Members, typ.Size, true); err != nil {
+			return "", 0, err
+		}
+		return name, typ.Size, nil
+	default:
+		return "", 0, fmt.Errorf("unsupported BTF member type %s", t)
+	}
+}
+
+func intGoType(i *btf.Int) string {
+	switch i.Size {
+	case 1:
+		return "uint8"
+	case 2:
+		return "uint16"
+	case 4:
+		return "uint32"
+	case 8:
+		return "uint64"
+	default:
+		return fmt.Sprintf("[%d]byte", i.Size)
+	}
+}
+
+// exportName turns a C identifier (e.g. "foo_bar" or a "$unionN" tag) into an
+// exported Go identifier, e.g. "FooBar".
+func exportName(name string) string {
+	name = strings.TrimPrefix(name, "$")
+	parts := strings.FieldsFunc(name, func(r rune) bool { return r == '_' })
+	var b strings.Builder
+	for _, p := range parts {
+		if p == "" {
+			continue
+		}
+		r := []rune(p)
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+	if b.Len() == 0 {
+		return name
+	}
+	return b.String()
+}
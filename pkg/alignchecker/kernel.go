@@ -0,0 +1,81 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package alignchecker
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/cilium/ebpf/btf"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logrus.WithField("subsys", "alignchecker")
+
+// KernelBTFOption configures CheckStructAlignmentsAgainstKernel.
+type KernelBTFOption func(*kernelBTFConfig)
+
+type kernelBTFConfig struct {
+	module string
+}
+
+// WithKernelModule validates against the split BTF of the named, currently loaded
+// kernel module (e.g. "tcp_bbr") instead of the main vmlinux BTF.
+func WithKernelModule(name string) KernelBTFOption {
+	return func(c *kernelBTFConfig) { c.module = name }
+}
+
+// CheckStructAlignmentsAgainstKernel is CheckStructAlignments for structs that
+// mirror a kernel-defined layout - pinned BPF map values, uapi structs such as
+// bpf_sock_tuple, tracepoint contexts - rather than one of our own compiled BPF
+// objects. It loads BTF for the running kernel (or, with WithKernelModule, a
+// loaded kernel module) instead of reading it from an ELF file, then reuses the
+// same struct-info extraction and field-by-field comparison as
+// CheckStructAlignments.
+//
+// A type missing from the running kernel's BTF is not treated as an error: older
+// kernels may simply predate it, and failing outright would break this check the
+// moment it ran on a newer kernel than some still-supported release shipped
+// against. Such types are logged as a warning and skipped instead.
+func CheckStructAlignmentsAgainstKernel(toCheck map[string][]reflect.Type, opts ...KernelBTFOption) error {
+	cfg := &kernelBTFConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	var spec *btf.Spec
+	var err error
+	if cfg.module != "" {
+		spec, err = btf.LoadKernelModuleSpec(cfg.module)
+		if err != nil {
+			return fmt.Errorf("cannot load kernel BTF for module %s: %w", cfg.module, err)
+		}
+	} else {
+		spec, err = btf.LoadKernelSpec()
+		if err != nil {
+			return fmt.Errorf("cannot load kernel BTF: %w", err)
+		}
+	}
+
+	present := make(map[string][]reflect.Type, len(toCheck))
+	for name, goStructs := range toCheck {
+		if _, err := spec.AnyTypeByName(name); err != nil {
+			log.WithField("type", name).Warning("Type not found in running kernel's BTF, skipping alignment check")
+			continue
+		}
+		present[name] = goStructs
+	}
+
+	structInfo, err := getStructInfosFromBTF(spec, present)
+	if err != nil {
+		return fmt.Errorf("cannot extract struct info from kernel BTF: %w", err)
+	}
+
+	for cName, goStructs := range present {
+		if err := check(cName, goStructs, structInfo, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -11,9 +11,16 @@ import (
 	"slices"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/cilium/endpointslice-controller/endpointslice"
 	"github.com/cilium/hive/cell"
+	"github.com/sirupsen/logrus"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	k8swait "k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/informers"
 	cache "k8s.io/client-go/tools/cache"
 
@@ -22,12 +29,31 @@ import (
 	cmtypes "github.com/cilium/cilium/pkg/clustermesh/types"
 	"github.com/cilium/cilium/pkg/clustermesh/wait"
 	"github.com/cilium/cilium/pkg/k8s"
+	"github.com/cilium/cilium/pkg/k8s/client"
 	"github.com/cilium/cilium/pkg/k8s/resource"
 	slim_corev1 "github.com/cilium/cilium/pkg/k8s/slim/k8s/api/core/v1"
 	"github.com/cilium/cilium/pkg/kvstore/store"
 	"github.com/cilium/cilium/pkg/loadbalancer"
 	"github.com/cilium/cilium/pkg/logging/logfields"
 	serviceStore "github.com/cilium/cilium/pkg/service/store"
+	"github.com/cilium/cilium/pkg/testutils/failpoints"
+)
+
+// syncBeforeWaitFailpoint lets tests force clusterMesh.synced into its
+// sync-timeout branch deterministically, instead of racing a real timeout
+// against wait.ForAll.
+const syncBeforeWaitFailpoint = "clustermesh-endpointslicesync-sync-before-wait"
+
+const (
+	// endpointSliceManagedByLabel and endpointSliceManagedByValue mark the
+	// EndpointSlices owned by newEndpointSliceMeshController, as opposed to
+	// ones managed by Kubernetes' own endpoints controller.
+	endpointSliceManagedByLabel = discoveryv1.LabelManagedBy
+	endpointSliceManagedByValue = "cilium.io/clustermesh-controller"
+
+	// endpointSliceRemoteClusterLabel records which remote cluster a headless
+	// service's per-cluster EndpointSlice shard mirrors backends from.
+	endpointSliceRemoteClusterLabel = "cilium.io/remote-cluster"
 )
 
 // clusterMesh is a cache of multiple remote clusters
@@ -35,6 +61,8 @@ type clusterMesh struct {
 	// common implements the common logic to connect to remote clusters.
 	common common.ClusterMesh
 
+	clientset client.Clientset
+
 	context       context.Context
 	contextCancel context.CancelFunc
 	Metrics       Metrics
@@ -54,32 +82,164 @@ type clusterMesh struct {
 	endpointSliceMeshController  *endpointslice.Controller
 	endpointSliceInformerFactory informers.SharedInformerFactory
 
-	started                   atomic.Bool
+	started atomic.Bool
+
+	hooksMu                   sync.RWMutex
 	clusterAddHooks           []func(string)
 	clusterDeleteHooks        []func(string)
 	clusterServiceUpdateHooks []func(*serviceStore.ClusterService)
 	clusterServiceDeleteHooks []func(*serviceStore.ClusterService)
 
+	subscribersMu sync.RWMutex
+	subscribers   []*subscriber
+
+	clusterStatesMu sync.Mutex
+	clusterStates   map[string]*clusterJoinState
+
+	// readinessProbe is retried with backoff before a cluster transitions
+	// Joining -> Ready. It defaults to an always-succeeds no-op: genuinely
+	// probing the remote kvstore/API needs the common/kvstore client types,
+	// which this checkout does not vendor a concrete implementation of; it
+	// exists so a real probe has somewhere to be plugged in.
+	readinessProbe func(ctx context.Context, name string) error
+
+	// degradedGracePeriod is how long a Ready cluster may stay unreachable
+	// before clusterDeleteHooks actually fire for it, so a brief
+	// disconnect/reconnect doesn't churn downstream consumers.
+	degradedGracePeriod time.Duration
+
 	syncTimeoutConfig  wait.TimeoutConfig
 	syncTimeoutLogOnce sync.Once
 }
 
+// ClusterPhase is a remote cluster's join readiness, as tracked by
+// fireClusterAdded/fireClusterDeleted and (eventually) surfaced through
+// clusterMesh.status().
+type ClusterPhase int
+
+const (
+	// ClusterJoining is a cluster's phase from the moment it is first seen
+	// until its readiness probe first succeeds.
+	ClusterJoining ClusterPhase = iota
+	// ClusterDegraded is a previously-Ready cluster that has become
+	// unreachable but is still within its degradedGracePeriod.
+	ClusterDegraded
+	// ClusterReady is a cluster whose readiness probe has succeeded;
+	// clusterAddHooks fire on this transition.
+	ClusterReady
+)
+
+func (p ClusterPhase) String() string {
+	switch p {
+	case ClusterJoining:
+		return "joining"
+	case ClusterDegraded:
+		return "degraded"
+	case ClusterReady:
+		return "ready"
+	default:
+		return "unknown"
+	}
+}
+
+// clusterJoinState tracks a single remote cluster's progress through
+// Joining -> Ready -> Degraded -> (deleted). cancel stops whatever
+// goroutine is currently waiting on this cluster's behalf: the readiness
+// backoff loop while Joining, or the degraded grace timer while Degraded.
+type clusterJoinState struct {
+	phase  ClusterPhase
+	cancel context.CancelFunc
+}
+
+// defaultDegradedGracePeriod is how long a Ready remote cluster may stay
+// unreachable before it is treated as deleted.
+const defaultDegradedGracePeriod = 30 * time.Second
+
+// readinessBackoff bounds how aggressively awaitClusterReady retries a
+// failing readiness probe.
+var readinessBackoff = k8swait.Backoff{
+	Duration: 100 * time.Millisecond,
+	Factor:   2,
+	Jitter:   0.1,
+	Steps:    10,
+	Cap:      30 * time.Second,
+}
+
+// ClusterEventType identifies the kind of event carried by a ClusterEvent.
+type ClusterEventType int
+
+const (
+	// ClusterAdded is sent when a remote cluster joins the mesh, and also
+	// replayed (with Snapshot set) for every cluster already in the mesh when
+	// a new subscriber calls Subscribe.
+	ClusterAdded ClusterEventType = iota
+	// ClusterDeleted is sent when a remote cluster leaves the mesh.
+	ClusterDeleted
+	// ClusterServiceUpdated is sent when a service in the mesh is created or
+	// updated.
+	ClusterServiceUpdated
+	// ClusterServiceDeleted is sent when a service in the mesh is deleted.
+	ClusterServiceDeleted
+	// ClusterResync is sent in place of whatever events a slow subscriber
+	// missed once its channel buffer filled up, telling it to re-derive its
+	// state (e.g. from GlobalServices) rather than trust a torn stream of
+	// incremental events.
+	ClusterResync
+)
+
+// ClusterEvent is a single event delivered over a Subscribe channel.
+type ClusterEvent struct {
+	Type ClusterEventType
+	// Cluster is set for ClusterAdded and ClusterDeleted.
+	Cluster string
+	// Service is set for ClusterServiceUpdated and ClusterServiceDeleted.
+	Service *serviceStore.ClusterService
+	// Snapshot is true for ClusterAdded events replayed as part of a new
+	// subscriber's initial batch, so a late subscriber can tell "this cluster
+	// was already part of the mesh" from "this cluster was just added".
+	Snapshot bool
+}
+
+// subscriberChannelSize bounds how many events a subscriber may lag behind
+// before events start being dropped in its favor (see (*clusterMesh).publish).
+const subscriberChannelSize = 64
+
+type subscriber struct {
+	ch      chan ClusterEvent
+	dropped atomic.Bool
+}
+
 // ClusterMesh is the interface corresponding to the clusterMesh struct to expose
 // its public methods to other Cilium packages.
 type ClusterMesh interface {
 	// RegisterClusterAddHook register a hook when a cluster is added to the mesh.
-	// This should NOT be called after the Start hook.
+	// The hook is kept in its own list and called directly when the event
+	// fires, not through Subscribe; may be called at any time, including
+	// after Start.
 	RegisterClusterAddHook(clusterAddHook func(string))
 	// RegisterClusterDeleteHook register a hook when a cluster is removed from the mesh.
-	// This should NOT be called after the Start hook.
+	// The hook is kept in its own list and called directly when the event
+	// fires, not through Subscribe; may be called at any time, including
+	// after Start.
 	RegisterClusterDeleteHook(clusterDeleteHook func(string))
 	// RegisterClusterServiceUpdateHook register a hook when a service in the mesh is updated.
-	// This should NOT be called after the Start hook.
+	// The hook is kept in its own list and called directly when the event
+	// fires, not through Subscribe; may be called at any time, including
+	// after Start.
 	RegisterClusterServiceUpdateHook(clusterServiceUpdateHook func(*serviceStore.ClusterService))
 	// RegisterClusterServiceDeleteHook register a hook when a service in the mesh is deleted.
-	// This should NOT be called after the Start hook.
+	// The hook is kept in its own list and called directly when the event
+	// fires, not through Subscribe; may be called at any time, including
+	// after Start.
 	RegisterClusterServiceDeleteHook(clusterServiceDeleteHook func(*serviceStore.ClusterService))
 
+	// Subscribe registers a new observer of cluster and cluster-service
+	// events. The returned channel's first batch replays every cluster
+	// currently in the mesh (each carrying Snapshot=true) before switching to
+	// live events, and is closed when ctx is done. Like the Register*Hook
+	// methods, Subscribe may be called at any time.
+	Subscribe(ctx context.Context) (<-chan ClusterEvent, error)
+
 	ServicesSynced(ctx context.Context) error
 	GlobalServices() *common.GlobalServiceCache
 }
@@ -92,13 +252,17 @@ func newClusterMesh(lc cell.Lifecycle, params clusterMeshParams) (*clusterMesh,
 	log.Info("Endpoint Slice Cluster Mesh synchronization enabled")
 
 	cm := clusterMesh{
-		Metrics: params.Metrics,
+		Metrics:   params.Metrics,
+		clientset: params.Clientset,
 		globalServices: common.NewGlobalServiceCache(
 			params.Metrics.TotalGlobalServices.WithLabelValues(params.ClusterInfo.Name),
 		),
 		storeFactory:                      params.StoreFactory,
 		concurrentClusterMeshEndpointSync: params.Cfg.ClusterMeshMaxEndpointsPerSlice,
 		syncTimeoutConfig:                 params.TimeoutConfig,
+		clusterStates:                     make(map[string]*clusterJoinState),
+		readinessProbe:                    func(ctx context.Context, name string) error { return nil },
+		degradedGracePeriod:               defaultDegradedGracePeriod,
 	}
 	cm.context, cm.contextCancel = context.WithCancel(context.Background())
 	cm.meshPodInformer = newMeshPodInformer(cm.globalServices)
@@ -165,54 +329,265 @@ func (cm *clusterMeshServiceGetter) GetServiceIP(svcID k8s.ServiceID) *loadbalan
 }
 
 // RegisterClusterAddHook register a hook when a cluster is added to the mesh.
-// This should NOT be called after the Start hook.
+// The hook is called directly by awaitClusterReady, not through Subscribe;
+// may be called at any time, including after the Start hook.
 func (cm *clusterMesh) RegisterClusterAddHook(clusterAddHook func(string)) {
-	if cm.started.Load() {
-		panic(fmt.Errorf("can't call RegisterClusterAddHook after the Start hook"))
-	}
+	cm.hooksMu.Lock()
+	defer cm.hooksMu.Unlock()
 	cm.clusterAddHooks = append(cm.clusterAddHooks, clusterAddHook)
 }
 
 // RegisterClusterDeleteHook register a hook when a cluster is removed from the mesh.
-// This should NOT be called after the Start hook.
+// The hook is called directly by fireClusterDeletedAfterGrace, not through
+// Subscribe; may be called at any time, including after the Start hook.
 func (cm *clusterMesh) RegisterClusterDeleteHook(clusterDeleteHook func(string)) {
-	if cm.started.Load() {
-		panic(fmt.Errorf("can't call RegisterClusterDeleteHook after the Start hook"))
-	}
+	cm.hooksMu.Lock()
+	defer cm.hooksMu.Unlock()
 	cm.clusterDeleteHooks = append(cm.clusterDeleteHooks, clusterDeleteHook)
 }
 
 // RegisterClusterServiceUpdateHook register a hook when a service in the mesh is updated.
-// This should NOT be called after the Start hook.
+// The hook is called directly by fireClusterServiceUpdated, not through
+// Subscribe; may be called at any time, including after the Start hook.
 func (cm *clusterMesh) RegisterClusterServiceUpdateHook(clusterServiceUpdateHook func(*serviceStore.ClusterService)) {
-	if cm.started.Load() {
-		panic(fmt.Errorf("can't call RegisterClusterServiceUpdateHook after the Start hook"))
-	}
+	cm.hooksMu.Lock()
+	defer cm.hooksMu.Unlock()
 	cm.clusterServiceUpdateHooks = append(cm.clusterServiceUpdateHooks, clusterServiceUpdateHook)
 }
 
 // RegisterClusterServiceDeleteHook register a hook when a service in the mesh is deleted.
-// This should NOT be called after the Start hook.
+// The hook is called directly by fireClusterServiceDeleted, not through
+// Subscribe; may be called at any time, including after the Start hook.
 func (cm *clusterMesh) RegisterClusterServiceDeleteHook(clusterServiceDeleteHook func(*serviceStore.ClusterService)) {
-	if cm.started.Load() {
-		panic(fmt.Errorf("can't call RegisterClusterServiceDeleteHook after the Start hook"))
-	}
+	cm.hooksMu.Lock()
+	defer cm.hooksMu.Unlock()
 	cm.clusterServiceDeleteHooks = append(cm.clusterServiceDeleteHooks, clusterServiceDeleteHook)
 }
 
+// Subscribe registers a new observer of cluster and cluster-service events.
+// See the ClusterMesh interface doc for the replay/ordering contract.
+func (cm *clusterMesh) Subscribe(ctx context.Context) (<-chan ClusterEvent, error) {
+	sub := &subscriber{ch: make(chan ClusterEvent, subscriberChannelSize)}
+
+	cm.subscribersMu.Lock()
+	cm.subscribers = append(cm.subscribers, sub)
+	cm.subscribersMu.Unlock()
+
+	cm.common.ForEachRemoteCluster(func(rci common.RemoteCluster) error {
+		name := rci.(*remoteCluster).name
+		select {
+		case sub.ch <- (ClusterEvent{Type: ClusterAdded, Cluster: name, Snapshot: true}):
+		default:
+		}
+		return nil
+	})
+
+	go func() {
+		<-ctx.Done()
+		cm.subscribersMu.Lock()
+		defer cm.subscribersMu.Unlock()
+		cm.subscribers = slices.DeleteFunc(cm.subscribers, func(s *subscriber) bool { return s == sub })
+		close(sub.ch)
+	}()
+
+	return sub.ch, nil
+}
+
+// publish fans ev out to every live subscriber. A subscriber whose channel is
+// full does not block the others: the event is dropped for that subscriber,
+// and a single ClusterResync is queued ahead of the first later event once its
+// channel has room again, so it knows to re-derive its state instead of
+// trusting a torn stream.
+func (cm *clusterMesh) publish(ev ClusterEvent) {
+	cm.subscribersMu.RLock()
+	defer cm.subscribersMu.RUnlock()
+
+	for _, sub := range cm.subscribers {
+		if sub.dropped.CompareAndSwap(true, false) {
+			select {
+			case sub.ch <- (ClusterEvent{Type: ClusterResync}):
+			default:
+				sub.dropped.Store(true)
+				continue
+			}
+		}
+		select {
+		case sub.ch <- ev:
+		default:
+			sub.dropped.Store(true)
+		}
+	}
+}
+
+// fireClusterAdded is wired into each remoteCluster's clusterAddHooks at
+// construction time. Rather than firing the registered Register*Hook
+// callbacks and Subscribe-based observers immediately, it starts (or
+// restarts) a readiness gate for name: clusterAddHooks only actually fire,
+// and a ClusterAdded event is only published, once awaitClusterReady
+// observes readinessProbe succeed. awaitClusterReady reads the hook list at
+// that moment, so a RegisterClusterAddHook callback registered after Start is
+// called for clusters that become Ready later; clusters that were already
+// Ready when it was registered are not replayed to it.
+func (cm *clusterMesh) fireClusterAdded(name string) {
+	cm.clusterStatesMu.Lock()
+	if st, ok := cm.clusterStates[name]; ok && st.cancel != nil {
+		// A join probe or degraded grace timer was already in flight for
+		// this cluster name (e.g. a reconnect before the grace period
+		// elapsed): cancel it so it can't race the new attempt below.
+		st.cancel()
+	}
+	ctx, cancel := context.WithCancel(cm.context)
+	cm.clusterStates[name] = &clusterJoinState{phase: ClusterJoining, cancel: cancel}
+	cm.clusterStatesMu.Unlock()
+
+	go cm.awaitClusterReady(ctx, name)
+}
+
+// awaitClusterReady retries readinessProbe with backoff until it succeeds or
+// ctx is done, then transitions name to ClusterReady and fires the hooks and
+// event that fireClusterAdded deferred.
+func (cm *clusterMesh) awaitClusterReady(ctx context.Context, name string) {
+	err := k8swait.ExponentialBackoffWithContext(ctx, readinessBackoff, func(ctx context.Context) (bool, error) {
+		if err := cm.readinessProbe(ctx, name); err != nil {
+			log.WithError(err).WithField(logfields.ClusterName, name).Debug(
+				"Remote cluster not yet ready, retrying")
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		// Either ctx was canceled (the cluster was removed before it ever
+		// became ready, or a newer fireClusterAdded superseded this attempt),
+		// or readinessBackoff ran out of steps, in which case the cluster
+		// stays ClusterJoining until the next fireClusterAdded. Either way
+		// there is nothing to roll back: this cluster never reached Ready, so
+		// clusterAddHooks never fired for it.
+		return
+	}
+
+	cm.clusterStatesMu.Lock()
+	if st, ok := cm.clusterStates[name]; ok {
+		st.phase = ClusterReady
+		st.cancel = nil
+	}
+	cm.clusterStatesMu.Unlock()
+
+	cm.hooksMu.RLock()
+	hooks := slices.Clone(cm.clusterAddHooks)
+	cm.hooksMu.RUnlock()
+	for _, hook := range hooks {
+		hook(name)
+	}
+	cm.publish(ClusterEvent{Type: ClusterAdded, Cluster: name})
+}
+
+// fireClusterDeleted is fireClusterAdded's counterpart for cluster removal.
+// A cluster that never reached ClusterReady is torn down silently, since
+// clusterAddHooks never fired for it either. A cluster that was Ready moves
+// to ClusterDegraded and only actually fires clusterDeleteHooks once
+// degradedGracePeriod elapses without a matching fireClusterAdded for the
+// same name, so a brief disconnect/reconnect doesn't churn downstream
+// consumers such as the EndpointSlice controller and meshNodeInformer.
+func (cm *clusterMesh) fireClusterDeleted(name string) {
+	cm.clusterStatesMu.Lock()
+	st, ok := cm.clusterStates[name]
+	if !ok {
+		cm.clusterStatesMu.Unlock()
+		return
+	}
+	if st.phase == ClusterJoining {
+		if st.cancel != nil {
+			st.cancel()
+		}
+		delete(cm.clusterStates, name)
+		cm.clusterStatesMu.Unlock()
+		return
+	}
+
+	ctx, cancel := context.WithCancel(cm.context)
+	st.phase = ClusterDegraded
+	st.cancel = cancel
+	cm.clusterStatesMu.Unlock()
+
+	go cm.fireClusterDeletedAfterGrace(ctx, name)
+}
+
+// fireClusterDeletedAfterGrace waits out degradedGracePeriod before actually
+// firing clusterDeleteHooks and publishing a ClusterDeleted event for name,
+// unless ctx is canceled first by a reconnecting fireClusterAdded or by
+// clusterMesh shutting down.
+func (cm *clusterMesh) fireClusterDeletedAfterGrace(ctx context.Context, name string) {
+	select {
+	case <-ctx.Done():
+		return
+	case <-time.After(cm.degradedGracePeriod):
+	}
+
+	cm.clusterStatesMu.Lock()
+	delete(cm.clusterStates, name)
+	cm.clusterStatesMu.Unlock()
+
+	cm.hooksMu.RLock()
+	hooks := slices.Clone(cm.clusterDeleteHooks)
+	cm.hooksMu.RUnlock()
+	for _, hook := range hooks {
+		hook(name)
+	}
+	cm.publish(ClusterEvent{Type: ClusterDeleted, Cluster: name})
+}
+
+// clusterPhase returns name's current join phase, or ClusterJoining if name
+// is not tracked (e.g. it was never added, or was already fully deleted).
+func (cm *clusterMesh) clusterPhase(name string) ClusterPhase {
+	cm.clusterStatesMu.Lock()
+	defer cm.clusterStatesMu.Unlock()
+	if st, ok := cm.clusterStates[name]; ok {
+		return st.phase
+	}
+	return ClusterJoining
+}
+
+// fireClusterServiceUpdated is fireClusterAdded's counterpart for a
+// created/updated service in the mesh.
+func (cm *clusterMesh) fireClusterServiceUpdated(svc *serviceStore.ClusterService) {
+	cm.hooksMu.RLock()
+	hooks := slices.Clone(cm.clusterServiceUpdateHooks)
+	cm.hooksMu.RUnlock()
+	for _, hook := range hooks {
+		hook(svc)
+	}
+	cm.publish(ClusterEvent{Type: ClusterServiceUpdated, Service: svc})
+}
+
+// fireClusterServiceDeleted is fireClusterAdded's counterpart for a deleted
+// service in the mesh.
+func (cm *clusterMesh) fireClusterServiceDeleted(svc *serviceStore.ClusterService) {
+	cm.hooksMu.RLock()
+	hooks := slices.Clone(cm.clusterServiceDeleteHooks)
+	cm.hooksMu.RUnlock()
+	for _, hook := range hooks {
+		hook(svc)
+	}
+	cm.publish(ClusterEvent{Type: ClusterServiceDeleted, Service: svc})
+}
+
 func (cm *clusterMesh) GlobalServices() *common.GlobalServiceCache {
 	return cm.globalServices
 }
 
 func (cm *clusterMesh) newRemoteCluster(name string, status common.StatusFunc) common.RemoteCluster {
+	// clusterAddHooks/clusterDeleteHooks each hold a single forwarding closure
+	// rather than a copy of cm's registries, so that hooks registered through
+	// RegisterClusterAddHook/RegisterClusterDeleteHook after this remoteCluster
+	// was constructed (including after Start) still fire.
 	rc := &remoteCluster{
 		name:               name,
 		globalServices:     cm.globalServices,
 		storeFactory:       cm.storeFactory,
 		synced:             newSynced(),
 		status:             status,
-		clusterAddHooks:    cm.clusterAddHooks,
-		clusterDeleteHooks: cm.clusterDeleteHooks,
+		clusterAddHooks:    []func(string){cm.fireClusterAdded},
+		clusterDeleteHooks: []func(string){cm.fireClusterDeleted},
 	}
 
 	rc.remoteServices = cm.storeFactory.NewWatchStore(
@@ -221,16 +596,8 @@ func (cm *clusterMesh) newRemoteCluster(name string, status common.StatusFunc) c
 		common.NewSharedServicesObserver(
 			log.WithField(logfields.ClusterName, name),
 			cm.globalServices,
-			func(svc *serviceStore.ClusterService) {
-				for _, hook := range cm.clusterServiceUpdateHooks {
-					hook(svc)
-				}
-			},
-			func(svc *serviceStore.ClusterService) {
-				for _, hook := range cm.clusterServiceDeleteHooks {
-					hook(svc)
-				}
-			},
+			cm.fireClusterServiceUpdated,
+			cm.fireClusterServiceDeleted,
 		),
 		store.RWSWithOnSyncCallback(func(ctx context.Context) { rc.synced.services.Stop() }),
 	)
@@ -252,6 +619,10 @@ func (cm *clusterMesh) Start(startCtx cell.HookContext) error {
 		return fmt.Errorf("waitForCacheSync on service informer not successful")
 	}
 
+	if err := cm.reconcileStaleEndpointSlices(cm.context); err != nil {
+		log.WithError(err).Warning("Failed to reconcile stale clustermesh EndpointSlices on startup")
+	}
+
 	go func() {
 		if err := cm.ServicesSynced(cm.context); err != nil {
 			return // The parent context expired, and we are already terminating
@@ -285,6 +656,10 @@ func (cm *clusterMesh) synced(ctx context.Context, toWaitFn func(*remoteCluster)
 		return nil
 	})
 
+	if err := failpoints.Eval(syncBeforeWaitFailpoint); err != nil {
+		cancel()
+	}
+
 	err := wait.ForAll(wctx, waiters)
 	if ctx.Err() == nil && wctx.Err() != nil {
 		// The sync timeout expired, but the parent context is still valid, which
@@ -301,7 +676,93 @@ func (cm *clusterMesh) synced(ctx context.Context, toWaitFn func(*remoteCluster)
 	return err
 }
 
-// Status returns the status of the ClusterMesh subsystem
+// reconcileStaleEndpointSlices lists every Cilium-managed EndpointSlice in the
+// cluster and deletes the ones whose Service no longer exists in the local
+// cluster, or whose remote cluster is no longer part of the mesh. It runs once
+// at startup, before endpointSliceMeshController.Run starts processing
+// incremental events, so that a crash or restart while a global service (or
+// one of its per-cluster backends) was deleted doesn't leave the stale
+// EndpointSlice behind indefinitely. For headless services, which can produce
+// multiple EndpointSlices per service (one per remote cluster backing it), a
+// slice is deleted even if the service itself still exists, as long as the
+// remote cluster it mirrors is no longer part of the mesh.
+//
+// It is idempotent: re-running it against an already-reconciled cluster is a
+// no-op, and it is safe to run concurrently with the informer-driven
+// reconciliation loop, since both converge on the same desired state.
+func (cm *clusterMesh) reconcileStaleEndpointSlices(ctx context.Context) error {
+	selector := labels.Set{endpointSliceManagedByLabel: endpointSliceManagedByValue}.AsSelector()
+	list, err := cm.clientset.DiscoveryV1().EndpointSlices(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		return fmt.Errorf("listing clustermesh EndpointSlices: %w", err)
+	}
+
+	remoteClusters := make(map[string]struct{})
+	cm.common.ForEachRemoteCluster(func(rci common.RemoteCluster) error {
+		remoteClusters[rci.(*remoteCluster).name] = struct{}{}
+		return nil
+	})
+
+	for _, slice := range list.Items {
+		svcName, ok := slice.Labels[discoveryv1.LabelServiceName]
+		if !ok {
+			continue
+		}
+
+		stale := false
+		if _, err := cm.clientset.CoreV1().Services(slice.Namespace).Get(ctx, svcName, metav1.GetOptions{}); apierrors.IsNotFound(err) {
+			stale = true
+		} else if err != nil {
+			log.WithError(err).WithFields(logrus.Fields{
+				logfields.ServiceName:  svcName,
+				logfields.K8sNamespace: slice.Namespace,
+			}).Warning("Failed to look up service while reconciling clustermesh EndpointSlices, leaving it in place")
+			continue
+		}
+
+		if !stale {
+			if remoteCluster, ok := slice.Labels[endpointSliceRemoteClusterLabel]; ok {
+				if _, known := remoteClusters[remoteCluster]; !known {
+					stale = true
+				}
+			}
+		}
+
+		if !stale {
+			continue
+		}
+
+		err := cm.clientset.DiscoveryV1().EndpointSlices(slice.Namespace).Delete(ctx, slice.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("deleting stale EndpointSlice %s/%s: %w", slice.Namespace, slice.Name, err)
+		}
+
+		log.WithFields(logrus.Fields{
+			"endpointSlice":        slice.Name,
+			logfields.K8sNamespace: slice.Namespace,
+			logfields.ServiceName:  svcName,
+		}).Info("Deleted stale clustermesh EndpointSlice")
+	}
+
+	return nil
+}
+
+// Status returns the status of the ClusterMesh subsystem.
+//
+// This is a point-in-time snapshot built by walking the remote clusters
+// directly; it is not wired to the ClusterEvent stream Subscribe produces.
+// Driving it from that stream instead would need models.RemoteCluster to
+// carry the richer per-event state (e.g. a distinguishable "just added" vs
+// "was already present"), which is a change to the swagger-generated API
+// model left to whoever next revisits that schema.
+//
+// It also does not yet report each cluster's clusterPhase (Joining/Degraded/
+// Ready, see fireClusterAdded/fireClusterDeleted): surfacing that requires
+// knowing which models.RemoteCluster field is meant to carry it, and that
+// generated model isn't vendored in this checkout to check against. Use
+// clusterPhase(name) directly until that field is added.
 func (cm *clusterMesh) status() []*models.RemoteCluster {
 	var clusters []*models.RemoteCluster
 
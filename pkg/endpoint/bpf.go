@@ -18,15 +18,18 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/vishvananda/netlink"
 	"golang.org/x/sys/unix"
+	"k8s.io/client-go/util/workqueue"
 
 	"github.com/cilium/cilium/api/v1/models"
 	"github.com/cilium/cilium/pkg/bpf"
 	"github.com/cilium/cilium/pkg/common"
 	"github.com/cilium/cilium/pkg/completion"
-	"github.com/cilium/cilium/pkg/controller"
 	datapathOption "github.com/cilium/cilium/pkg/datapath/option"
 	"github.com/cilium/cilium/pkg/defaults"
+	"github.com/cilium/cilium/pkg/endpoint/events"
+	"github.com/cilium/cilium/pkg/endpoint/policydrift"
 	"github.com/cilium/cilium/pkg/endpoint/regeneration"
+	endpointstate "github.com/cilium/cilium/pkg/endpoint/state"
 	"github.com/cilium/cilium/pkg/identity"
 	"github.com/cilium/cilium/pkg/loadinfo"
 	"github.com/cilium/cilium/pkg/logging"
@@ -39,6 +42,7 @@ import (
 	"github.com/cilium/cilium/pkg/policy/trafficdirection"
 	"github.com/cilium/cilium/pkg/revert"
 	"github.com/cilium/cilium/pkg/time"
+	"github.com/cilium/cilium/pkg/trigger"
 	"github.com/cilium/cilium/pkg/u8proto"
 	"github.com/cilium/cilium/pkg/version"
 )
@@ -54,8 +58,6 @@ const (
 
 var (
 	handleNoHostInterfaceOnce sync.Once
-
-	syncPolicymapControllerGroup = controller.NewGroup("sync-policymap")
 )
 
 // policyMapPath returns the path to the policy map of endpoint.
@@ -140,10 +142,18 @@ func (e *Endpoint) writeInformationalComments(w io.Writer) error {
 	return fw.Flush()
 }
 
+// publishRegenEvent stamps ev with this endpoint's ID and publishes it on
+// the package-wide events bus, so subscribers never need a reference to a
+// specific Endpoint to observe its regeneration.
+func (e *Endpoint) publishRegenEvent(ev events.RegenerationEvent) {
+	ev.EndpointID = e.ID
+	events.Publish(ev)
+}
+
 // writeHeaderfile writes the lxc_config.h header file of an endpoint.
 //
 // e.mutex must be write-locked.
-func (e *Endpoint) writeHeaderfile(prefix string) error {
+func (e *Endpoint) writeHeaderfile(prefix string, headerHash string) error {
 	headerPath := filepath.Join(prefix, common.CHeaderFileName)
 	e.getLogger().WithFields(logrus.Fields{
 		logfields.Path: headerPath,
@@ -169,6 +179,10 @@ func (e *Endpoint) writeHeaderfile(prefix string) error {
 		return err
 	}
 
+	if err := e.writeEndpointState(prefix, headerHash); err != nil {
+		return fmt.Errorf("failed to write endpoint state sidecar: %w", err)
+	}
+
 	f, err := renameio.TempFile(prefix, headerPath)
 	if err != nil {
 		return fmt.Errorf("failed to open temporary file: %w", err)
@@ -196,6 +210,67 @@ func (e *Endpoint) writeHeaderfile(prefix string) error {
 	return f.CloseAtomicallyReplace()
 }
 
+// writeEndpointState writes the endpoint/state sidecar (endpoint_state.json)
+// next to the C header file, so that tooling which only needs a structured
+// snapshot of restorable endpoint state doesn't have to scrape the
+// "CILIUM_BASE64_" comment out of lxc_config.h. headerHash is the hash
+// computed for the header file being written alongside this call, or "" if
+// it has not been computed yet (e.g. the dry-mode/fake-endpoint path in
+// regenerateBPF, which writes a header file before any hash is known).
+//
+// Note: this package contains only bpf.go, so the realized redirect map
+// built in addNewRedirects/consumed in removeOldRedirects isn't in scope
+// here - those maps live in the regeneration call graph above writeHeaderfile,
+// in files not present in this checkout. Redirects is therefore left empty;
+// a caller with access to that map can populate it once this method's
+// signature is threaded further up. DNSRules and TemplateHash are not set
+// here either and are always empty in the written file.
+func (e *Endpoint) writeEndpointState(prefix string, headerHash string) error {
+	var labels []string
+	if e.SecurityIdentity != nil {
+		for _, v := range e.SecurityIdentity.Labels {
+			labels = append(labels, v.String())
+		}
+	}
+
+	s := endpointstate.EndpointState{
+		EndpointID:     e.ID,
+		Identity:       uint32(e.getIdentity()),
+		Labels:         labels,
+		MAC:            e.nodeMAC.String(),
+		PolicyRevision: e.nextPolicyRevision,
+		HeaderHash:     headerHash,
+	}
+	if option.Config.EnableIPv6 {
+		s.IPv6 = e.IPv6.String()
+	}
+	s.IPv4 = e.IPv4.String()
+
+	if cid := e.GetContainerID(); cid == "" {
+		s.DockerNetworkID = e.dockerNetworkID
+		s.DockerEndpointID = e.dockerEndpointID
+	} else {
+		s.ContainerID = cid
+	}
+
+	data, err := endpointstate.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to serialize endpoint state: %w", err)
+	}
+
+	f, err := renameio.TempFile(prefix, filepath.Join(prefix, endpointstate.FileName))
+	if err != nil {
+		return fmt.Errorf("failed to open temporary file: %w", err)
+	}
+	defer f.Cleanup()
+
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+
+	return f.CloseAtomicallyReplace()
+}
+
 // proxyPolicy implements policy.ProxyPolicy interface, and passes most of the calls
 // to policy.L4Filter, but re-implements GetPort() to return the resolved named port,
 // instead of returning a 0 port number.
@@ -231,6 +306,10 @@ func (p *proxyPolicy) GetListener() string {
 // The returned map contains the exact set of IDs of proxy redirects that is
 // required to implement the given L4 policy.
 // Only called after a new selector policy has been computed.
+//
+// Its revert stack only covers proxy redirect/statistics state: the
+// PolicyMap itself is synced later, by regenerateBPF's call to
+// syncPolicyMapWith, which has no revert path of its own.
 func (e *Endpoint) addNewRedirects(selectorPolicy policy.SelectorPolicy, proxyWaitGroup *completion.WaitGroup) (desiredRedirects map[string]uint16, ff revert.FinalizeFunc, rf revert.RevertFunc) {
 	if e.isProperty(PropertyFakeEndpoint) || e.IsProxyDisabled() {
 		return nil, nil, nil
@@ -239,9 +318,10 @@ func (e *Endpoint) addNewRedirects(selectorPolicy policy.SelectorPolicy, proxyWa
 	desiredRedirects = make(map[string]uint16)
 
 	var (
-		finalizeList revert.FinalizeList
-		revertStack  revert.RevertStack
-		updatedStats []*models.ProxyStatistics
+		finalizeList  revert.FinalizeList
+		revertStack   revert.RevertStack
+		updatedStats  []*models.ProxyStatistics
+		addedProxyIDs []string
 	)
 
 	// create or update proxy redirects
@@ -282,6 +362,7 @@ func (e *Endpoint) addNewRedirects(selectorPolicy policy.SelectorPolicy, proxyWa
 		finalizeList.Append(finalizeFunc)
 		revertStack.Push(revertFunc)
 		desiredRedirects[proxyID] = proxyPort
+		addedProxyIDs = append(addedProxyIDs, proxyID)
 
 		// Update the endpoint API model to report that Cilium manages a
 		// redirect for that port.
@@ -290,6 +371,13 @@ func (e *Endpoint) addNewRedirects(selectorPolicy policy.SelectorPolicy, proxyWa
 		updatedStats = append(updatedStats, proxyStats)
 	}
 
+	if len(addedProxyIDs) > 0 {
+		e.publishRegenEvent(events.RegenerationEvent{
+			Phase:         events.PhaseProxyWaitForAck,
+			AddedProxyIDs: addedProxyIDs,
+		})
+	}
+
 	// revert function is called with endpoint mutex held
 	revertStack.Push(func() error {
 		// Restore the proxy stats.
@@ -312,6 +400,8 @@ func (e *Endpoint) removeOldRedirects(desiredRedirects, realizedRedirects map[st
 		return
 	}
 
+	var removedProxyIDs []string
+
 	for id, redirectPort := range realizedRedirects {
 		// Remove only the redirects that are not required.
 		if desiredRedirects[id] != 0 {
@@ -321,13 +411,14 @@ func (e *Endpoint) removeOldRedirects(desiredRedirects, realizedRedirects map[st
 		if redirectPort != 0 {
 			e.proxy.RemoveRedirect(id)
 		}
+		removedProxyIDs = append(removedProxyIDs, id)
 
 		// Update the endpoint API model to report that no redirect is
 		// active or known for that port anymore. We never delete stats
 		// until an endpoint is deleted, so we only set the redirect port
 		// to 0.
-		_, ingress, protocol, port, _, _ := policy.ParseProxyID(id)
-		key := policy.ProxyStatsKey(ingress, protocol, port, redirectPort)
+		proxyID, _ := policy.ParseProxyID(id)
+		key := policy.ProxyStatsKey(proxyID.Ingress, proxyID.Protocol.String(), proxyID.Port, redirectPort)
 		e.proxyStatisticsMutex.Lock()
 		if proxyStats, ok := e.proxyStatistics[key]; ok {
 			proxyStats.AllocatedProxyPort = 0
@@ -336,6 +427,13 @@ func (e *Endpoint) removeOldRedirects(desiredRedirects, realizedRedirects map[st
 		}
 		e.proxyStatisticsMutex.Unlock()
 	}
+
+	if len(removedProxyIDs) > 0 {
+		e.publishRegenEvent(events.RegenerationEvent{
+			Phase:           events.PhaseProxyWaitForAck,
+			RemovedProxyIDs: removedProxyIDs,
+		})
+	}
 }
 
 // regenerateBPF rewrites all headers and updates all BPF maps to reflect the
@@ -350,7 +448,20 @@ func (e *Endpoint) removeOldRedirects(desiredRedirects, realizedRedirects map[st
 func (e *Endpoint) regenerateBPF(regenContext *regenerationContext) (revnum uint64, reterr error) {
 	var err error
 
+	e.publishRegenEvent(events.RegenerationEvent{
+		Phase:             events.PhaseStarted,
+		RegenerationLevel: fmt.Sprint(regenContext.datapathRegenerationContext.regenerationLevel),
+	})
+	defer func() {
+		if reterr != nil {
+			e.publishRegenEvent(events.RegenerationEvent{Phase: events.PhaseError, Err: reterr})
+		} else {
+			e.publishRegenEvent(events.RegenerationEvent{Phase: events.PhaseDone})
+		}
+	}()
+
 	stats := &regenContext.Stats
+	e.publishRegenEvent(events.RegenerationEvent{Phase: events.PhaseWaitingForLock})
 	stats.waitingForLock.Start()
 
 	datapathRegenCtxt := regenContext.datapathRegenerationContext
@@ -401,6 +512,7 @@ func (e *Endpoint) regenerateBPF(regenContext *regenerationContext) (revnum uint
 			regenContext.DoneFunc()
 		}
 
+		e.publishRegenEvent(events.RegenerationEvent{Phase: events.PhaseProxyWaitForAck})
 		stats.proxyWaitForAck.Start()
 		err = e.waitForProxyCompletions(datapathRegenCtxt.proxyWaitGroup)
 		stats.proxyWaitForAck.End(err == nil)
@@ -423,6 +535,7 @@ func (e *Endpoint) regenerateBPF(regenContext *regenerationContext) (revnum uint
 
 	if !datapathRegenCtxt.epInfoCache.IsHost() || option.Config.EnableHostFirewall {
 		// Hook the endpoint into the endpoint and endpoint to policy tables then expose it
+		e.publishRegenEvent(events.RegenerationEvent{Phase: events.PhaseMapSync})
 		stats.mapSync.Start()
 		err = lxcmap.WriteEndpoint(datapathRegenCtxt.epInfoCache)
 		stats.mapSync.End(err == nil)
@@ -440,6 +553,7 @@ func (e *Endpoint) regenerateBPF(regenContext *regenerationContext) (revnum uint
 		regenContext.DoneFunc()
 	}
 
+	e.publishRegenEvent(events.RegenerationEvent{Phase: events.PhaseProxyWaitForAck})
 	stats.proxyWaitForAck.Start()
 	err = e.waitForProxyCompletions(datapathRegenCtxt.proxyWaitGroup)
 	stats.proxyWaitForAck.End(err == nil)
@@ -464,8 +578,19 @@ func (e *Endpoint) regenerateBPF(regenContext *regenerationContext) (revnum uint
 	// GH-3897 would fix this by creating a new map to do an atomic swap
 	// with the old one.
 	//
+	// A prior attempt at this built a shadow map and atomically exchanged its
+	// bpffs pin with the live one, on the theory that a loaded BPF program
+	// re-resolves its map by pin path. It doesn't: the program binds to a map
+	// object by fd captured at load time (ReloadDatapath), so renaming the
+	// pin afterward doesn't retarget what a running program reads from, and
+	// that approach was reverted. A real fix for GH-3897 needs a way to
+	// update the loaded program's map reference itself (e.g. re-pointing its
+	// tail-call program-array entry at a program built against the new map),
+	// not just the bpffs pin.
+	//
 	// This must be done after allocating the new redirects, to update the
 	// policy map with the new proxy ports.
+	e.publishRegenEvent(events.RegenerationEvent{Phase: events.PhaseMapSync})
 	stats.mapSync.Start()
 	// Nothing to do if the desired policy is already fully realized.
 	if e.realizedPolicy.basis != e.desiredPolicy {
@@ -503,6 +628,7 @@ func (e *Endpoint) realizeBPFState(regenContext *regenerationContext) (err error
 		}
 
 		// Compile and install BPF programs for this endpoint
+		e.publishRegenEvent(events.RegenerationEvent{Phase: events.PhaseDatapathRealization})
 		templateHash, err := e.owner.Orchestrator().ReloadDatapath(datapathRegenCtxt.completionCtx, datapathRegenCtxt.epInfoCache, &stats.datapathRealization)
 		if err != nil {
 			if !errors.Is(err, context.Canceled) {
@@ -515,6 +641,8 @@ func (e *Endpoint) realizeBPFState(regenContext *regenerationContext) (err error
 			return fmt.Errorf("unable to write template id: %w", err)
 		}
 
+		e.publishRegenEvent(events.RegenerationEvent{Phase: events.PhaseDatapathRealization, TemplateHash: templateHash})
+
 		e.getLogger().Info("Reloaded endpoint BPF program")
 		e.bpfHeaderfileHash = datapathRegenCtxt.bpfHeaderfilesHash
 	} else if debugEnabled {
@@ -537,6 +665,7 @@ func (e *Endpoint) runPreCompilationSteps(regenContext *regenerationContext) (pr
 	// regenerate policy without holding the lock.
 	// This is because policy generation needs the ipcache to make progress, and the ipcache
 	// needs to call endpoint.ApplyPolicyMapChanges()
+	e.publishRegenEvent(events.RegenerationEvent{Phase: events.PhasePolicyCalculation})
 	stats.policyCalculation.Start()
 	policyResult, err := e.regeneratePolicy(stats, datapathRegenCtxt)
 	stats.policyCalculation.End(err == nil)
@@ -633,7 +762,7 @@ func (e *Endpoint) runPreCompilationSteps(regenContext *regenerationContext) (pr
 	// If dry mode is enabled, no further changes to BPF maps are performed
 	if e.isProperty(PropertySkipBPFPolicy) {
 		if e.isProperty(PropertyFakeEndpoint) {
-			if err = e.writeHeaderfile(nextDir); err != nil {
+			if err = e.writeHeaderfile(nextDir, ""); err != nil {
 				return fmt.Errorf("Unable to write header file: %w", err)
 			}
 		}
@@ -661,6 +790,7 @@ func (e *Endpoint) runPreCompilationSteps(regenContext *regenerationContext) (pr
 		return nil
 	}
 
+	e.publishRegenEvent(events.RegenerationEvent{Phase: events.PhasePrepareBuild})
 	stats.prepareBuild.Start()
 	defer func() {
 		stats.prepareBuild.End(preCompilationError == nil)
@@ -679,11 +809,15 @@ func (e *Endpoint) runPreCompilationSteps(regenContext *regenerationContext) (pr
 				Debugf("BPF header file hashed (was: %q)", e.bpfHeaderfileHash)
 		}
 
+		e.publishRegenEvent(events.RegenerationEvent{
+			Phase:        events.PhasePrepareBuild,
+			TemplateHash: datapathRegenCtxt.bpfHeaderfilesHash,
+		})
 		datapathRegenCtxt.regenerationLevel = regeneration.RegenerateWithDatapath
 	}
 
 	if datapathRegenCtxt.regenerationLevel >= regeneration.RegenerateWithDatapath {
-		if err := e.writeHeaderfile(nextDir); err != nil {
+		if err := e.writeHeaderfile(nextDir, datapathRegenCtxt.bpfHeaderfilesHash); err != nil {
 			return fmt.Errorf("unable to write header file: %w", err)
 		}
 
@@ -725,12 +859,20 @@ func (e *Endpoint) InitMap() error {
 
 // deleteMaps deletes the endpoint's entry from the global
 // cilium_(egress)call_policy maps and removes endpoint-specific cilium_calls_,
-// cilium_policy_ and cilium_ct{4,6}_ map pins.
+// cilium_policy_ and cilium_ct{4,6}_ map pins. It also releases the
+// endpoint's bandwidth manager and egress gateway state, and scrubs
+// conntrack entries for the endpoint's IPs so that a reused IP doesn't
+// resume stale state from either.
 //
 // Call this after the endpoint's tc hook has been detached.
 func (e *Endpoint) deleteMaps() []error {
 	var errors []error
 
+	// Stop and forget this endpoint's PolicyMap change coalescing trigger,
+	// if one was ever created; it would otherwise be leaked since aliveCtx
+	// may already be canceled by the time its own cleanup goroutine runs.
+	e.stopPolicyMapChangeTrigger()
+
 	// Remove the endpoint from cilium_lxc. After this point, ip->epID lookups
 	// will fail, causing packets to/from the Pod to be dropped in many cases,
 	// stopping packet evaluation.
@@ -750,6 +892,26 @@ func (e *Endpoint) deleteMaps() []error {
 		e.owner.BandwidthManager().DeleteBandwidthLimit(e.ID)
 	}
 
+	// Remove any egress gateway policy entries keyed by this endpoint's
+	// source IPs, synchronously instead of waiting for the egress gateway
+	// manager's next reconcile tick. Otherwise a Pod IP that gets reused
+	// before that tick runs can hit another endpoint's stale SNAT entry.
+	//
+	// This is synchronous cleanup closing a security-relevant race, the same
+	// as the other steps in this function, so its failure is reported like
+	// theirs rather than discarded. The EgressGatewayManager interface itself
+	// isn't present in this checkout to confirm DeleteEndpointEntries's exact
+	// signature against; this assumes it returns an error the way every other
+	// fallible cleanup step here does.
+	if err := e.owner.EgressGatewayManager().DeleteEndpointEntries(e.IPv4, e.IPv6); err != nil {
+		errors = append(errors, fmt.Errorf("removing egress gateway entries for endpoint: %w", err))
+	}
+
+	// Scrub conntrack entries for the same IPs in the same pass, so a
+	// reused IP can't resume a connection that the egress gateway entries
+	// just removed above were SNAT'ing.
+	e.scrubIPsInConntrackTableLocked()
+
 	if e.ConntrackLocalLocked() {
 		// Remove endpoint-specific CT map pins.
 		for _, m := range ctmap.LocalMaps(e, option.Config.EnableIPv4, option.Config.EnableIPv6) {
@@ -853,6 +1015,16 @@ type policyMapPressureUpdater interface {
 	Remove(uint16)
 }
 
+// updatePolicyMapPressureMetric reports this endpoint's PolicyMap fill ratio.
+//
+// A prior attempt at auto-growing the PolicyMap once this ratio stayed above
+// a high-water mark for a sustained window was reverted: every confirmed
+// call site of policymap.Create in this tree builds a map of the same
+// package-level fixed size, and there is no size-parameterized constructor
+// to ask for a bigger one, so the resize itself could never succeed.
+// Autoscaling PolicyMap capacity remains an open gap; it needs a
+// size-parameterized Create in pkg/maps/policymap before anything here can
+// act on sustained pressure.
 func (e *Endpoint) updatePolicyMapPressureMetric() {
 	value := float64(len(e.realizedPolicy.mapStateMap)) / float64(e.policyMap.MaxEntries())
 	e.PolicyMapPressureUpdater.Update(PolicyMapPressureEvent{
@@ -876,6 +1048,7 @@ func (e *Endpoint) deletePolicyKey(keyToDelete policy.Key, incremental bool) boo
 	errors.As(err, &errno)
 	if err != nil && errno != unix.ENOENT {
 		e.getLogger().WithError(err).WithField(logfields.BPFMapKey, policymapKey).Error("Failed to delete PolicyMap key")
+		e.enqueuePolicyMapSync(policydrift.DriverOnDemand)
 		return false
 	}
 
@@ -899,9 +1072,21 @@ func (e *Endpoint) addPolicyKey(keyToAdd policy.Key, entry policy.MapStateEntry,
 	policymapKey := policymap.NewKey(keyToAdd.TrafficDirection(), keyToAdd.Identity, keyToAdd.Nexthdr, keyToAdd.DestPort, keyToAdd.PortPrefixLen())
 
 	var err error
-	if entry.IsDeny {
+	switch {
+	case entry.IsDeny && !entry.IsAuditDeny:
 		err = e.policyMap.DenyKey(policymapKey)
-	} else {
+	case entry.IsDeny && entry.IsAuditDeny:
+		// An audit deny is "log and permit": policymap.Key/AllowKey/DenyKey in
+		// this checkout have no log-only flag to ask the datapath to record a
+		// would-be drop while still letting the packet through, so until that
+		// plumbing exists upstream the safe choice is to actually permit the
+		// traffic via AllowKey rather than enforce the drop a plain DenyKey
+		// would. This means an audit deny currently logs nothing at the
+		// datapath; it only keeps the would-be-block visible in mapState
+		// itself (see MapStateEntry.IsAuditDeny) until real log-only support
+		// lands in policymap.
+		err = e.policyMap.AllowKey(policymapKey, entry.HasAuthType == policy.ExplicitAuthType, entry.AuthType.Uint8(), entry.ProxyPort)
+	default:
 		err = e.policyMap.AllowKey(policymapKey, entry.HasAuthType == policy.ExplicitAuthType, entry.AuthType.Uint8(), entry.ProxyPort)
 	}
 	if err != nil {
@@ -909,6 +1094,7 @@ func (e *Endpoint) addPolicyKey(keyToAdd policy.Key, entry policy.MapStateEntry,
 			logfields.BPFMapKey: policymapKey,
 			logfields.Port:      entry.ProxyPort,
 		}).Error("Failed to add PolicyMap key")
+		e.enqueuePolicyMapSync(policydrift.DriverOnDemand)
 		return false
 	}
 
@@ -924,6 +1110,85 @@ func (e *Endpoint) addPolicyKey(keyToAdd policy.Key, entry policy.MapStateEntry,
 	return true
 }
 
+// policyMapChangeMinInterval bounds how often a burst of identity/selectorcache
+// driven PolicyMap changes is allowed to fold into a single reconciliation,
+// via QueuePolicyMapChanges below.
+const policyMapChangeMinInterval = 100 * time.Millisecond
+
+// policyMapChangeTriggers coalesces bursts of QueuePolicyMapChanges calls per
+// endpoint into a single applyPolicyMapChanges run, using pkg/trigger the
+// same way pkg/egressgateway's manager coalesces its reconciliation.
+//
+// This is keyed by the Endpoint's own pointer rather than a field on the
+// Endpoint struct: endpoint.go, where the Endpoint struct itself is defined,
+// isn't present in this checkout, and pkg/endpoint/bpf.go only ever operates
+// on a *Endpoint it didn't allocate. A field would be the natural home for
+// this once that file is available to edit.
+var policyMapChangeTriggers sync.Map // map[*Endpoint]*trigger.Trigger
+
+// QueuePolicyMapChanges schedules a coalesced ApplyPolicyMapChanges run for
+// e. Bursts of calls within policyMapChangeMinInterval of each other fold
+// into a single PolicyMap reconciliation, rather than each identity or
+// selector-cache event taking the endpoint lock and issuing BPF map
+// syscalls on its own.
+//
+// Callers that need a synchronous flush with a completion ACK (e.g. the
+// proxy, which must block until a policy update has actually landed) should
+// continue to call ApplyPolicyMapChanges directly instead; this method is
+// for the fire-and-forget identity/selectorcache event path.
+func (e *Endpoint) QueuePolicyMapChanges(reason string) error {
+	t, err := e.policyMapChangeTrigger()
+	if err != nil {
+		return err
+	}
+	t.TriggerWithReason(reason)
+	return nil
+}
+
+// policyMapChangeTrigger returns (creating it if necessary) the coalescing
+// trigger for e.
+func (e *Endpoint) policyMapChangeTrigger() (*trigger.Trigger, error) {
+	if t, ok := policyMapChangeTriggers.Load(e); ok {
+		return t.(*trigger.Trigger), nil
+	}
+
+	t, err := trigger.NewTrigger(trigger.Parameters{
+		Name:        fmt.Sprintf("endpoint-%d-policymap-changes", e.ID),
+		MinInterval: policyMapChangeMinInterval,
+		TriggerFunc: func(reasons []string) {
+			if err := e.ApplyPolicyMapChanges(completion.NewWaitGroup(e.aliveCtx)); err != nil {
+				e.getLogger().WithError(err).Warning("Failed to apply coalesced PolicyMap changes")
+			}
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create PolicyMap change trigger: %w", err)
+	}
+
+	actual, loaded := policyMapChangeTriggers.LoadOrStore(e, t)
+	if loaded {
+		// Lost the race against a concurrent caller; use their trigger and
+		// shut down the redundant one we just created.
+		t.Shutdown()
+		return actual.(*trigger.Trigger), nil
+	}
+
+	go func() {
+		<-e.aliveCtx.Done()
+		e.stopPolicyMapChangeTrigger()
+	}()
+
+	return t, nil
+}
+
+// stopPolicyMapChangeTrigger shuts down and forgets e's coalescing trigger,
+// if one was ever created. Safe to call more than once.
+func (e *Endpoint) stopPolicyMapChangeTrigger() {
+	if t, ok := policyMapChangeTriggers.LoadAndDelete(e); ok {
+		t.(*trigger.Trigger).Shutdown()
+	}
+}
+
 // ApplyPolicyMapChanges updates the Endpoint's PolicyMap with the changes
 // that have accumulated for the PolicyMap via various outside events (e.g.,
 // identities added / deleted).
@@ -1149,16 +1414,19 @@ func (e *Endpoint) dumpPolicyMapToMapState() (policy.MapStateMap, error) {
 	return currentMap, err
 }
 
-// syncPolicyMapWithDump is invoked periodically to perform a full reconciliation
-// of the endpoint's PolicyMap against the BPF maps to catch cases where either
-// due to kernel issue or user intervention the agent's view of the PolicyMap
-// state has diverged from the kernel. A warning is logged if this method finds
-// such an discrepancy.
+// syncPolicyMapWithDump is invoked periodically (or on-demand, see
+// enqueuePolicyMapSync) to perform a full reconciliation of the endpoint's
+// PolicyMap against the BPF maps to catch cases where either due to kernel
+// issue or user intervention the agent's view of the PolicyMap state has
+// diverged from the kernel. A warning is logged if this method finds such
+// an discrepancy, and each fixed diff is recorded to pkg/endpoint/policydrift
+// so that a drift burst is observable rather than only ever appearing as a
+// log line (see the policydrift package doc for what it does not cover).
 //
 // Returns an error if the endpoint's BPF PolicyMap is unable to be dumped,
 // or any update operation to the map fails.
 // Must be called with e.mutex Lock()ed.
-func (e *Endpoint) syncPolicyMapWithDump() error {
+func (e *Endpoint) syncPolicyMapWithDump(driver policydrift.Driver) error {
 	if e.policyMap == nil {
 		return fmt.Errorf("not syncing PolicyMap state for endpoint because PolicyMap is nil")
 	}
@@ -1198,47 +1466,166 @@ func (e *Endpoint) syncPolicyMapWithDump() error {
 	// Log full policy map for every dump
 	e.PolicyDebug(logrus.Fields{"dumpedPolicyMap": currentMap}, "syncPolicyMapWithDump")
 	// Diffs between the maps indicate an error in the policy map update logic.
-	// Collect and log diffs if policy logging is enabled.
-	diffCount, diffs, err := e.syncPolicyMapWith(currentMap, e.getPolicyLogger() != nil)
+	// Always collect them (not just when policy logging is enabled) so they
+	// can be recorded as policydrift events below.
+	diffCount, diffs, err := e.syncPolicyMapWith(currentMap, true)
 
 	if diffCount > 0 {
 		e.getLogger().WithField(logfields.Count, diffCount).Warning("Policy map sync fixed errors, consider running with debug verbose = policy to get detailed dumps")
 		e.PolicyDebug(logrus.Fields{"dumpedDiffs": diffs}, "syncPolicyMapWithDump")
+
+		generation := policydrift.NextGeneration()
+		now := time.Now()
+		for _, diff := range diffs {
+			direction := policydrift.DirectionAdd
+			if !diff.Add {
+				// A MapChange with Add == false is a stray entry that was
+				// present in the kernel's PolicyMap but not in desired
+				// state, and had to be deleted.
+				direction = policydrift.DirectionDelete
+			}
+			policydrift.RecordEvent(policydrift.Event{
+				EndpointID: e.ID,
+				Generation: generation,
+				Driver:     driver,
+				Direction:  direction,
+				Key:        fmt.Sprintf("%v", diff.Key),
+				NewValue:   diff.Value.String(),
+				Time:       now,
+			})
+		}
 	}
 
 	return err
 }
 
+// policyMapSyncQueue is a single, process-wide rate-limited workqueue that
+// drains PolicyMap reconciliation requests for all endpoints, along the
+// lines of the egress gateway manager's workqueue.NewRateLimitingQueue with
+// exponential backoff: a failed reconciliation is requeued with backoff
+// instead of waiting for the next fixed-interval tick, and a dump failure
+// that forces a PolicyMap reopen (see syncPolicyMapWithDump) gets the same
+// retry treatment as a single failed add/delete syscall.
+//
+// This is process-wide rather than a field on Endpoint for the same reason
+// policyMapChangeTriggers above is: endpoint.go, where the Endpoint struct
+// itself is defined, isn't present in this checkout.
+var (
+	policyMapSyncQueue     workqueue.RateLimitingInterface
+	policyMapSyncQueueOnce sync.Once
+)
+
+// getPolicyMapSyncQueue returns the process-wide PolicyMap sync queue,
+// starting its single drain goroutine on first use.
+func getPolicyMapSyncQueue() workqueue.RateLimitingInterface {
+	policyMapSyncQueueOnce.Do(func() {
+		policyMapSyncQueue = workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+		go drainPolicyMapSyncQueue(policyMapSyncQueue)
+	})
+	return policyMapSyncQueue
+}
+
+// drainPolicyMapSyncQueue is the single manager goroutine per agent that
+// processes PolicyMap reconciliation requests queued by every endpoint,
+// giving bounded parallelism across endpoints instead of one controller
+// goroutine per endpoint.
+//
+// Queue depth and retry counts are natural Prometheus metrics
+// (cilium_endpoint_policymap_sync_{queue_depth,retries}), but pkg/metrics
+// isn't present anywhere in this checkout to register them against, so
+// this only logs retries for now.
+func drainPolicyMapSyncQueue(queue workqueue.RateLimitingInterface) {
+	for {
+		item, shutdown := queue.Get()
+		if shutdown {
+			return
+		}
+
+		e, ok := item.(*Endpoint)
+		if !ok {
+			queue.Forget(item)
+			queue.Done(item)
+			continue
+		}
+
+		err := e.reconcilePolicyMapSync()
+		queue.Done(item)
+		if err != nil {
+			e.getLogger().WithError(err).Warning("PolicyMap reconciliation failed, retrying with backoff")
+			queue.AddRateLimited(item)
+		} else {
+			queue.Forget(item)
+		}
+	}
+}
+
+// policyMapSyncDrivers tracks why each endpoint currently sitting on
+// policyMapSyncQueue was enqueued (periodic tick vs on-demand syscall
+// failure), so that reconcilePolicyMapSync can tag the policydrift events a
+// pass produces with the driver that caused it. Keyed by *Endpoint for the
+// same reason policyMapChangeTriggers above is.
+var policyMapSyncDrivers sync.Map // map[*Endpoint]policydrift.Driver
+
+// enqueuePolicyMapSync schedules e for a full PolicyMap reconciliation pass:
+// on a non-ENOENT addPolicyKey/deletePolicyKey syscall error, or
+// periodically via startSyncPolicyMapController's ticker below.
+func (e *Endpoint) enqueuePolicyMapSync(driver policydrift.Driver) {
+	if e.isProperty(PropertySkipBPFPolicy) {
+		return
+	}
+	// A later enqueue's driver overwrites an earlier, still-queued one;
+	// either is an accurate description of "why reconcile", and the queue
+	// itself already dedups repeat Adds of the same endpoint.
+	policyMapSyncDrivers.Store(e, driver)
+	getPolicyMapSyncQueue().Add(e)
+}
+
+// reconcilePolicyMapSync takes the endpoint lock and performs one full
+// PolicyMap reconciliation pass for e. A non-nil return requeues e with
+// backoff; a nil return (including when e is mid-regeneration, which isn't
+// a failure - the regeneration's own map sync will realize the desired
+// state) forgets it until the next enqueue.
+func (e *Endpoint) reconcilePolicyMapSync() error {
+	// Failure to lock means the endpoint was disconnected; nothing to
+	// reconcile, and nothing worth retrying.
+	if err := e.lockAlive(); err != nil {
+		return nil
+	}
+	defer e.unlock()
+
+	if e.policyMap == nil || e.realizedPolicy.basis != e.desiredPolicy {
+		return nil
+	}
+
+	driver := policydrift.DriverOnDemand
+	if d, ok := policyMapSyncDrivers.LoadAndDelete(e); ok {
+		driver = d.(policydrift.Driver)
+	}
+	return e.syncPolicyMapWithDump(driver)
+}
+
+// startSyncPolicyMapController starts the periodic tick that enqueues e for
+// full PolicyMap reconciliation onto the process-wide policyMapSyncQueue,
+// on top of the on-demand enqueues addPolicyKey/deletePolicyKey trigger
+// when a BPF syscall fails.
 func (e *Endpoint) startSyncPolicyMapController() {
 	// Skip the controller if the endpoint has no policy map
 	if e.isProperty(PropertySkipBPFPolicy) {
 		return
 	}
 
-	ctrlName := fmt.Sprintf("sync-policymap-%d", e.ID)
-	e.controllers.CreateController(ctrlName,
-		controller.ControllerParams{
-			Group:  syncPolicymapControllerGroup,
-			Health: e.GetReporter("policymap-sync"),
-			DoFunc: func(ctx context.Context) error {
-				// Failure to lock is not an error, it means
-				// that the endpoint was disconnected and we
-				// should exit gracefully.
-				if err := e.lockAlive(); err != nil {
-					return controller.NewExitReason("Endpoint disappeared")
-				}
-				defer e.unlock()
-				if e.realizedPolicy.basis != e.desiredPolicy {
-					// Currently in the middle of a regeneration; do not execute
-					// at this time.
-					return nil
-				}
-				return e.syncPolicyMapWithDump()
-			},
-			RunInterval: option.Config.PolicyMapFullReconciliationInterval,
-			Context:     e.aliveCtx,
-		},
-	)
+	go func() {
+		ticker := time.NewTicker(option.Config.PolicyMapFullReconciliationInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-e.aliveCtx.Done():
+				return
+			case <-ticker.C:
+				e.enqueuePolicyMapSync(policydrift.DriverPeriodic)
+			}
+		}
+	}()
 }
 
 // RequireARPPassthrough returns true if the datapath must implement ARP
@@ -0,0 +1,166 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+// Package events publishes a structured stream of endpoint regeneration
+// events, so that subsystems other than the regeneration code itself
+// (monitor, hubble, health, or an interactive debugger) can observe a stuck
+// or flapping regeneration without grepping logs.
+//
+// This package only covers the publish/subscribe bus itself. The request
+// that motivated it also asked for two things that don't fit this
+// checkout:
+//
+//   - OpenTelemetry spans per regeneration phase, via go.opentelemetry.io/otel.
+//     That module isn't vendored anywhere in this tree (pkg/endpoint/bpf.go,
+//     the only file in the regeneration call graph present here, imports
+//     nothing from go.opentelemetry.io), and adding a brand new tracing
+//     dependency by hand without being able to fetch/verify it against a
+//     real module cache would be guesswork. RegenerationEvent carries
+//     enough information (endpoint ID, phase, timestamps are the caller's
+//     responsibility) that a tracing exporter can be layered on top of a
+//     Subscribe call later without changing this package.
+//   - A `/v1/endpoint/{id}/regeneration/stream` SSE API. The generated API
+//     server/router code (api/v1/server) and the daemon package that would
+//     register a new route are both absent from this checkout, so there is
+//     nowhere to wire a handler. An SSE handler is a thin adapter over
+//     Subscribe (range over the channel, write one `data: ...\n\n` frame per
+//     event) and can be added wherever the daemon's route table actually
+//     lives.
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// Phase identifies a stage of endpoint regeneration. The names mirror the
+// SpanStat fields already tracked in regenContext.Stats (see bpf.go).
+type Phase string
+
+const (
+	PhaseWaitingForLock      Phase = "waiting-for-lock"
+	PhasePolicyCalculation   Phase = "policy-calculation"
+	PhaseMapSync             Phase = "map-sync"
+	PhaseProxyWaitForAck     Phase = "proxy-wait-for-ack"
+	PhaseDatapathRealization Phase = "datapath-realization"
+	PhasePrepareBuild        Phase = "prepare-build"
+
+	// PhaseStarted and PhaseDone/PhaseError bracket a single regeneration,
+	// rather than naming one of its internal phases.
+	PhaseStarted Phase = "started"
+	PhaseDone    Phase = "done"
+	PhaseError   Phase = "error"
+)
+
+// RegenerationEvent describes one phase transition of one endpoint's
+// regeneration.
+type RegenerationEvent struct {
+	EndpointID uint16
+	Phase      Phase
+
+	// RegenerationLevel is the regeneration.RegenerationLevel name in
+	// effect for this regeneration, as a string so this package doesn't
+	// need to import the regeneration package for a single field. It is set
+	// on the PhaseStarted event only.
+	RegenerationLevel string
+
+	// TemplateHash is set on the PhasePrepareBuild event to the new BPF
+	// header file hash when it differs from the previously realized one, and
+	// on the PhaseDatapathRealization event that follows each datapath
+	// reload to the template hash that reload returned, whether or not it
+	// changed.
+	TemplateHash string
+
+	// AddedProxyIDs / RemovedProxyIDs are the proxy.ProxyID strings
+	// (see policy.ProxyID) that this regeneration is creating or tearing
+	// down redirects for.
+	AddedProxyIDs   []string
+	RemovedProxyIDs []string
+
+	// Err is set only for a terminal PhaseError event.
+	Err error
+}
+
+// subscriberChannelSize bounds how many events a slow subscriber can fall
+// behind by before being dropped, mirroring the clustermesh event bus.
+const subscriberChannelSize = 64
+
+// Bus is a channel-based, multi-subscriber publisher of RegenerationEvents.
+// The zero value is not usable; construct one with NewBus.
+type Bus struct {
+	mu          sync.Mutex
+	subscribers map[uint64]chan RegenerationEvent
+	nextID      uint64
+}
+
+// NewBus returns a ready-to-use Bus.
+func NewBus() *Bus {
+	return &Bus{
+		subscribers: make(map[uint64]chan RegenerationEvent),
+	}
+}
+
+// Subscribe registers a new subscriber and returns a channel of events along
+// with an unsubscribe function. The returned channel is closed once
+// unsubscribe is called or ctx is canceled, whichever happens first.
+//
+// A subscriber that falls behind has its channel dropped and closed rather
+// than blocking publishers: regeneration must never stall waiting for a
+// slow observer to drain its event stream.
+func (b *Bus) Subscribe(ctx context.Context) (<-chan RegenerationEvent, func()) {
+	ch := make(chan RegenerationEvent, subscriberChannelSize)
+
+	b.mu.Lock()
+	id := b.nextID
+	b.nextID++
+	b.subscribers[id] = ch
+	b.mu.Unlock()
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		if sub, ok := b.subscribers[id]; ok {
+			delete(b.subscribers, id)
+			close(sub)
+		}
+		b.mu.Unlock()
+	}
+
+	go func() {
+		<-ctx.Done()
+		unsubscribe()
+	}()
+
+	return ch, unsubscribe
+}
+
+// Publish delivers ev to every current subscriber. A subscriber whose
+// channel is full is dropped rather than blocked on.
+func (b *Bus) Publish(ev RegenerationEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for id, ch := range b.subscribers {
+		select {
+		case ch <- ev:
+		default:
+			delete(b.subscribers, id)
+			close(ch)
+		}
+	}
+}
+
+// defaultBus is the process-wide bus used by Publish/Subscribe. A process
+// only ever runs one cilium-agent, so a package-level bus (rather than a
+// field threaded through the Endpoint struct, which isn't defined in this
+// checkout) is the natural home for it.
+var defaultBus = NewBus()
+
+// Subscribe registers a new subscriber on the default bus. See Bus.Subscribe.
+func Subscribe(ctx context.Context) (<-chan RegenerationEvent, func()) {
+	return defaultBus.Subscribe(ctx)
+}
+
+// Publish delivers ev to every subscriber of the default bus. See Bus.Publish.
+func Publish(ev RegenerationEvent) {
+	defaultBus.Publish(ev)
+}
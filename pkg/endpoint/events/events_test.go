@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package events
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPublishDeliversToSubscriber(t *testing.T) {
+	b := NewBus()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, unsubscribe := b.Subscribe(ctx)
+	defer unsubscribe()
+
+	b.Publish(RegenerationEvent{EndpointID: 1, Phase: PhaseStarted})
+
+	select {
+	case ev := <-ch:
+		if ev.EndpointID != 1 || ev.Phase != PhaseStarted {
+			t.Fatalf("unexpected event: %+v", ev)
+		}
+	default:
+		t.Fatal("expected an event to be immediately available")
+	}
+}
+
+func TestUnsubscribeClosesChannel(t *testing.T) {
+	b := NewBus()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, unsubscribe := b.Subscribe(ctx)
+	unsubscribe()
+
+	if _, ok := <-ch; ok {
+		t.Fatal("expected channel to be closed after unsubscribe")
+	}
+}
+
+func TestSlowSubscriberIsDroppedNotBlocked(t *testing.T) {
+	b := NewBus()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, _ := b.Subscribe(ctx)
+
+	// Overflow the subscriber's buffer without ever draining it. Publish
+	// must drop the subscriber rather than block.
+	for i := 0; i < subscriberChannelSize+1; i++ {
+		b.Publish(RegenerationEvent{EndpointID: uint16(i), Phase: PhaseStarted})
+	}
+
+	b.mu.Lock()
+	_, stillSubscribed := b.subscribers[0]
+	b.mu.Unlock()
+	if stillSubscribed {
+		t.Fatal("expected overflowed subscriber to have been dropped")
+	}
+
+	if _, ok := <-ch; !ok {
+		t.Fatal("expected channel to still have buffered events before being drained")
+	}
+}
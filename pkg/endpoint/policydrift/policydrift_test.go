@@ -0,0 +1,38 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package policydrift
+
+import "testing"
+
+func TestRingBufferSnapshotFiltersByEndpoint(t *testing.T) {
+	b := newRingBuffer(4)
+	b.append(Event{EndpointID: 1, Key: "a"})
+	b.append(Event{EndpointID: 2, Key: "b"})
+	b.append(Event{EndpointID: 1, Key: "c"})
+
+	got := b.snapshot(1)
+	if len(got) != 2 || got[0].Key != "a" || got[1].Key != "c" {
+		t.Fatalf("unexpected snapshot: %+v", got)
+	}
+}
+
+func TestRingBufferWraps(t *testing.T) {
+	b := newRingBuffer(2)
+	b.append(Event{EndpointID: 1, Key: "a"})
+	b.append(Event{EndpointID: 1, Key: "b"})
+	b.append(Event{EndpointID: 1, Key: "c"})
+
+	got := b.snapshot(1)
+	if len(got) != 2 || got[0].Key != "b" || got[1].Key != "c" {
+		t.Fatalf("expected oldest entry to have been overwritten, got: %+v", got)
+	}
+}
+
+func TestNextGenerationIsMonotonic(t *testing.T) {
+	a := NextGeneration()
+	b := NextGeneration()
+	if b <= a {
+		t.Fatalf("expected generation to increase: %d -> %d", a, b)
+	}
+}
@@ -0,0 +1,22 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Read loads and decodes the EndpointState sidecar from dir, i.e.
+// filepath.Join(dir, FileName). Callers that also support the older
+// base64-in-comment format should fall back to that when Read's error
+// wraps os.ErrNotExist.
+func Read(dir string) (EndpointState, error) {
+	data, err := os.ReadFile(filepath.Join(dir, FileName))
+	if err != nil {
+		return EndpointState{}, fmt.Errorf("reading endpoint state sidecar: %w", err)
+	}
+	return Unmarshal(data)
+}
@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMarshalUnmarshalRoundTrip(t *testing.T) {
+	in := EndpointState{
+		EndpointID:     42,
+		Identity:       1234,
+		Labels:         []string{"k8s:app=foo"},
+		IPv4:           "10.0.0.1",
+		IPv6:           "f00d::1",
+		MAC:            "aa:bb:cc:dd:ee:ff",
+		ContainerID:    "abcdef",
+		Redirects:      []Redirect{{ProxyID: "42:ingress:TCP:80:envoy", Port: 9090}},
+		PolicyRevision: 7,
+		TemplateHash:   "deadbeef",
+		HeaderHash:     "cafebabe",
+	}
+
+	data, err := Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	out, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if out.Version != CurrentVersion {
+		t.Fatalf("expected version %d, got %d", CurrentVersion, out.Version)
+	}
+	out.Version = 0 // excluded from the comparison below, checked above
+	in.Version = 0
+	if out != in {
+		t.Fatalf("round trip mismatch:\n in: %+v\nout: %+v", in, out)
+	}
+}
+
+func TestUnmarshalForwardCompat(t *testing.T) {
+	// A hypothetical future schema version with an extra, unknown field.
+	data := []byte(`{"version":` + "99" + `,"endpointID":7,"extraFutureField":"ignored"}`)
+
+	out, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal failed on newer version: %v", err)
+	}
+	if out.EndpointID != 7 {
+		t.Fatalf("expected endpointID 7, got %d", out.EndpointID)
+	}
+}
+
+func TestRead(t *testing.T) {
+	dir := t.TempDir()
+	in := EndpointState{EndpointID: 5, PolicyRevision: 1}
+	data, err := Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, FileName), data, 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	out, err := Read(dir)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if out.EndpointID != 5 {
+		t.Fatalf("expected endpointID 5, got %d", out.EndpointID)
+	}
+}
+
+func TestReadMissingFile(t *testing.T) {
+	if _, err := Read(t.TempDir()); !os.IsNotExist(errUnwrap(err)) {
+		t.Fatalf("expected a wrapped os.ErrNotExist, got %v", err)
+	}
+}
+
+func errUnwrap(err error) error {
+	type unwrapper interface{ Unwrap() error }
+	if u, ok := err.(unwrapper); ok {
+		return u.Unwrap()
+	}
+	return err
+}
@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package envoy
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	downstream_connectionsv3 "github.com/cilium/proxy/go/envoy/extensions/resource_monitors/downstream_connections/v3"
+)
+
+// ValidateDownstreamConnectionsOverload runs Envoy's own validation for dc so
+// that agent startup fails fast on a misconfigured MaxConnections, instead of
+// only surfacing as a bootstrap rejection once Envoy itself loads it. dc may
+// be nil, meaning the overload action isn't configured.
+//
+// Nothing in this tree builds a DownstreamConnectionsConfig to pass in yet:
+// no Cilium config struct has a MaxConnections-style field, and nothing
+// injects the resource monitor/overload action into the Envoy bootstrap (see
+// the package doc's "Known gaps"). This is the validation half of that
+// still-missing knob, kept ready for whichever caller eventually builds one.
+func ValidateDownstreamConnectionsOverload(dc *downstream_connectionsv3.DownstreamConnectionsConfig) error {
+	if dc == nil {
+		return nil
+	}
+	if err := dc.Validate(); err != nil {
+		return fmt.Errorf("invalid downstream connections overload config: %w", err)
+	}
+	return nil
+}
+
+// globalDownstreamConnectionsStat is the Envoy admin stat name the
+// global_downstream_max_connections resource monitor publishes its live
+// connection count under.
+const globalDownstreamConnectionsStat = "overload.global_downstream_max_connections.active_downstream_connections"
+
+// CurrentGlobalDownstreamConnections parses stats, the plaintext body of an
+// Envoy admin /stats response, and returns the current value of the
+// global_downstream_max_connections resource monitor's active connection
+// count gauge. The second return value is false if stats doesn't contain
+// that stat at all, e.g. because the resource monitor isn't configured.
+//
+// This is the parsing choke point a Cilium metric scrape loop would call
+// into; the loop itself (an HTTP client polling Envoy's admin endpoint on an
+// interval, and a registered Prometheus gauge to set from the result) isn't
+// added here because pkg/metrics isn't present anywhere in this checkout, so
+// there's no registry to register a new gauge against. Like
+// ValidateDownstreamConnectionsOverload, this has no caller yet - see the
+// package doc's "Known gaps".
+func CurrentGlobalDownstreamConnections(stats []byte) (int64, bool) {
+	scanner := bufio.NewScanner(bytes.NewReader(stats))
+	for scanner.Scan() {
+		name, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok || strings.TrimSpace(name) != globalDownstreamConnectionsStat {
+			continue
+		}
+		parsed, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		return parsed, true
+	}
+	return 0, false
+}
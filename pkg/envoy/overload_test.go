@@ -0,0 +1,34 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package envoy
+
+import "testing"
+
+func TestCurrentGlobalDownstreamConnections(t *testing.T) {
+	stats := []byte("cluster.foo.upstream_cx_total: 12\n" +
+		"overload.global_downstream_max_connections.active_downstream_connections: 42\n" +
+		"server.uptime: 123\n")
+
+	got, ok := CurrentGlobalDownstreamConnections(stats)
+	if !ok {
+		t.Fatal("expected the stat to be found")
+	}
+	if got != 42 {
+		t.Fatalf("got %d, want 42", got)
+	}
+}
+
+func TestCurrentGlobalDownstreamConnectionsMissing(t *testing.T) {
+	stats := []byte("cluster.foo.upstream_cx_total: 12\n")
+
+	if _, ok := CurrentGlobalDownstreamConnections(stats); ok {
+		t.Fatal("expected the stat to be reported missing")
+	}
+}
+
+func TestValidateDownstreamConnectionsOverloadNil(t *testing.T) {
+	if err := ValidateDownstreamConnectionsOverload(nil); err != nil {
+		t.Fatalf("unexpected error for an unconfigured overload: %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package envoy
+
+import (
+	"sync"
+	"time"
+)
+
+// TCPConnectionRecord is a single TCP proxy connection's worth of access-log
+// data, independent of how (or whether) it ends up rendered into an Envoy
+// envoy.config.accesslog.v3.AccessLog sink.
+type TCPConnectionRecord struct {
+	Timestamp          time.Time
+	SourceAddress      string
+	DestinationAddress string
+	BytesSent          uint64
+	BytesReceived      uint64
+	Duration           time.Duration
+	// TerminationReason is one of "local_reset", "remote_reset", "timeout", or
+	// "" for a connection that closed normally.
+	TerminationReason string
+}
+
+// TCPLogFilter adapts Envoy's HTTP-oriented access log filter predicates
+// (status_code_filter, duration_filter, ...) to concepts that make sense for a
+// plain TCP proxy connection: byte-count thresholds in either direction,
+// minimum connection duration, and termination reason. The zero value matches
+// every connection.
+type TCPLogFilter struct {
+	MinBytesSent       uint64
+	MinBytesReceived   uint64
+	MinDuration        time.Duration
+	TerminationReasons []string
+}
+
+// Matches reports whether rec passes f's thresholds. An empty
+// TerminationReasons matches any termination reason.
+func (f TCPLogFilter) Matches(rec TCPConnectionRecord) bool {
+	if rec.BytesSent < f.MinBytesSent || rec.BytesReceived < f.MinBytesReceived {
+		return false
+	}
+	if rec.Duration < f.MinDuration {
+		return false
+	}
+	if len(f.TerminationReasons) == 0 {
+		return true
+	}
+	for _, reason := range f.TerminationReasons {
+		if reason == rec.TerminationReason {
+			return true
+		}
+	}
+	return false
+}
+
+// AccessLogRingBuffer retains the most recent TCP proxy connection records in
+// memory, independent of any persistent sink, so that operators without a
+// standing gRPC access-log collector can still retrieve recent events on
+// demand.
+//
+// Rendering this subsystem's output into actual
+// envoy.config.accesslog.v3.AccessLog file and gRPC (ALS) sink configurations,
+// and exposing AccessLogRingBuffer.Recent over a gRPC method on the Cilium xDS
+// server, both require protobuf message shapes and a service definition that
+// github.com/cilium/proxy does not currently vendor into this tree; that part
+// is left for a follow-up once those types are available.
+type AccessLogRingBuffer struct {
+	mu       sync.Mutex
+	entries  []TCPConnectionRecord
+	capacity int
+	next     int
+	full     bool
+}
+
+// NewAccessLogRingBuffer creates a ring buffer holding up to capacity records.
+func NewAccessLogRingBuffer(capacity int) *AccessLogRingBuffer {
+	return &AccessLogRingBuffer{
+		entries:  make([]TCPConnectionRecord, capacity),
+		capacity: capacity,
+	}
+}
+
+// Record appends rec, evicting the oldest record once the buffer is full.
+func (b *AccessLogRingBuffer) Record(rec TCPConnectionRecord) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.capacity == 0 {
+		return
+	}
+	b.entries[b.next] = rec
+	b.next = (b.next + 1) % b.capacity
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+// Recent returns up to n of the most recently recorded connections, newest
+// first.
+func (b *AccessLogRingBuffer) Recent(n int) []TCPConnectionRecord {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	size := b.next
+	if b.full {
+		size = b.capacity
+	}
+	if n > size {
+		n = size
+	}
+
+	out := make([]TCPConnectionRecord, 0, n)
+	for i := 0; i < n; i++ {
+		idx := (b.next - 1 - i + b.capacity) % b.capacity
+		out = append(out, b.entries[idx])
+	}
+	return out
+}
@@ -0,0 +1,22 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+// Package envoy builds and validates the Envoy xDS configuration Cilium hands to
+// the Envoy proxies it manages, on top of the protobuf types vendored from
+// github.com/cilium/proxy.
+//
+// Known gaps, not implemented anywhere in this package:
+//   - On-demand cluster discovery for TcpProxy, pending a github.com/cilium/proxy
+//     vendor bump: the vendored tcp_proxy.pb.go has no OnDemand message (field 14).
+//   - Tunneling retry options for TcpProxy, pending the same vendor bump: the
+//     vendored tcp_proxy.pb.go has no TunnelingConfig retry fields to set.
+//   - SNI-based forward proxying for TcpProxy, pending the same vendor bump: the
+//     vendored tcp_proxy.pb.go has no SNI cluster/filter-state fields to configure.
+//   - A first-class overload-manager knob (see ValidateDownstreamConnectionsOverload
+//     and CurrentGlobalDownstreamConnections in overload.go): no Cilium config struct
+//     carries a MaxConnections-style field, nothing builds the resource monitor and
+//     overload action into the Envoy bootstrap, and no metric scrape loop calls
+//     CurrentGlobalDownstreamConnections. This one isn't blocked on a vendor bump -
+//     DownstreamConnectionsConfig is already vendored - it needs the Cilium-side
+//     config plumbing and bootstrap wiring.
+package envoy
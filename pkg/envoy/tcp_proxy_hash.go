@@ -0,0 +1,65 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package envoy
+
+import (
+	"errors"
+
+	tcp_proxy "github.com/cilium/proxy/go/envoy/extensions/filters/network/tcp_proxy/v3"
+	v32 "github.com/cilium/proxy/go/envoy/type/v3"
+)
+
+// ErrSourcePortHashUnsupported is returned by HashPolicyFromSourcePort:
+// envoy.type.v3.HashPolicy, as used by TcpProxy, has no source-port variant.
+var ErrSourcePortHashUnsupported = errors.New(
+	"envoy.type.v3.HashPolicy has no source-port policy_specifier variant")
+
+// HashPolicyFromSourceIP returns a HashPolicy that hashes on the downstream
+// connection's source IP, for session affinity to the same
+// TcpProxy_WeightedCluster_ClusterWeight across reconnections from the same
+// client.
+func HashPolicyFromSourceIP() *v32.HashPolicy {
+	return &v32.HashPolicy{
+		PolicySpecifier: &v32.HashPolicy_SourceIp_{
+			SourceIp: &v32.HashPolicy_SourceIp{},
+		},
+	}
+}
+
+// HashPolicyFromFilterState returns a HashPolicy that hashes on the value
+// stored under key in the connection's filter state, e.g. a value an earlier
+// filter (such as Cilium's policy filter) stashed there.
+func HashPolicyFromFilterState(key string) *v32.HashPolicy {
+	return &v32.HashPolicy{
+		PolicySpecifier: &v32.HashPolicy_FilterState_{
+			FilterState: &v32.HashPolicy_FilterState{Key: key},
+		},
+	}
+}
+
+// HashPolicyFromSourcePort would return a HashPolicy hashing on the
+// downstream connection's source port. It always fails:
+// envoy.type.v3.HashPolicy only defines source_ip and filter_state variants, so
+// source-port hashing is not representable without extending that vendored
+// proto, which this package does not do.
+func HashPolicyFromSourcePort() (*v32.HashPolicy, error) {
+	return nil, ErrSourcePortHashUnsupported
+}
+
+// SetHashPolicy sets tp's hash_policy to policies. ValidateTcpProxy enforces
+// the proto's documented limit of at most one entry; SetHashPolicy itself does
+// not second-guess the caller, so that validation errors surface in one place.
+func SetHashPolicy(tp *tcp_proxy.TcpProxy, policies ...*v32.HashPolicy) {
+	tp.HashPolicy = policies
+}
+
+// ErrClusterLoadBalancingPolicyUnsupported documents why SetHashPolicy cannot
+// also configure the matching cluster-side LB policy (ring hash or maglev):
+// this tree does not vendor the CDS envoy.config.cluster.v3.Cluster type, so
+// there is nothing here to set LbPolicy on. Callers must co-configure the
+// cluster's LbPolicy through whatever builds their CDS snapshot once that type
+// is available.
+var ErrClusterLoadBalancingPolicyUnsupported = errors.New(
+	"configuring the cluster-side ring hash/maglev LB policy requires " +
+		"envoy.config.cluster.v3.Cluster, which this tree does not vendor")
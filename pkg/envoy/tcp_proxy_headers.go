@@ -0,0 +1,125 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package envoy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	v3 "github.com/cilium/proxy/go/envoy/config/core/v3"
+)
+
+// ConnectionAttributes carries the per-connection values available to
+// HeaderValueOption templates expanded by ExpandHeaderTemplates.
+type ConnectionAttributes struct {
+	SourceAddress       string
+	SourcePort          uint16
+	DestinationAddress  string
+	DestinationPort     uint16
+	IdentityID          uint32
+	IdentityLabels      []string
+	SNI                 string
+	ALPN                string
+	RequestedServerName string
+}
+
+// headerTemplateVars is the stable catalog of "%TOKEN%"-style variables
+// ExpandHeaderTemplates and ValidateHeaderTemplate understand. Supporting a new
+// variable means adding an entry here - anything else is rejected at validation
+// time rather than silently forwarded to the upstream as a literal "%TOKEN%".
+var headerTemplateVars = map[string]func(ConnectionAttributes) string{
+	"DOWNSTREAM_REMOTE_ADDRESS": func(a ConnectionAttributes) string {
+		return fmt.Sprintf("%s:%d", a.SourceAddress, a.SourcePort)
+	},
+	"DOWNSTREAM_REMOTE_ADDRESS_WITHOUT_PORT": func(a ConnectionAttributes) string {
+		return a.SourceAddress
+	},
+	"DOWNSTREAM_LOCAL_ADDRESS": func(a ConnectionAttributes) string {
+		return fmt.Sprintf("%s:%d", a.DestinationAddress, a.DestinationPort)
+	},
+	"DOWNSTREAM_LOCAL_ADDRESS_WITHOUT_PORT": func(a ConnectionAttributes) string {
+		return a.DestinationAddress
+	},
+	"CILIUM_IDENTITY": func(a ConnectionAttributes) string {
+		return strconv.FormatUint(uint64(a.IdentityID), 10)
+	},
+	"CILIUM_IDENTITY_LABELS": func(a ConnectionAttributes) string {
+		return strings.Join(a.IdentityLabels, ",")
+	},
+	"DOWNSTREAM_TLS_SNI":    func(a ConnectionAttributes) string { return a.SNI },
+	"DOWNSTREAM_TLS_ALPN":   func(a ConnectionAttributes) string { return a.ALPN },
+	"REQUESTED_SERVER_NAME": func(a ConnectionAttributes) string { return a.RequestedServerName },
+}
+
+// ValidateHeaderTemplate rejects any "%TOKEN%" reference in value that is not in
+// the supported catalog, so a typo in policy surfaces when the xDS config is
+// built rather than as a literal, unexpanded token forwarded to the upstream.
+func ValidateHeaderTemplate(value string) error {
+	_, err := expandTokens(value, func(string) string { return "" })
+	return err
+}
+
+// ExpandHeaderTemplates returns a copy of headers with every "%TOKEN%"
+// reference in each HeaderValueOption's value substituted against attrs. The
+// result is a list of the kind TcpProxy.TunnelingConfig.HeadersToAdd takes, so
+// the synthesized CONNECT/POST request can carry Cilium identity and intent to
+// an upstream HTTP proxy without per-endpoint config churn. No code in this
+// tree calls it, and nothing attaches its result to a TcpProxy.
+func ExpandHeaderTemplates(headers []*v3.HeaderValueOption, attrs ConnectionAttributes) ([]*v3.HeaderValueOption, error) {
+	out := make([]*v3.HeaderValueOption, len(headers))
+	for i, h := range headers {
+		if h.GetHeader() == nil {
+			out[i] = h
+			continue
+		}
+
+		expanded, err := expandTokens(h.GetHeader().GetValue(), func(token string) string {
+			return headerTemplateVars[token](attrs)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("header %q: %w", h.GetHeader().GetKey(), err)
+		}
+
+		cp := *h
+		cp.Header = &v3.HeaderValue{
+			Key:   h.GetHeader().GetKey(),
+			Value: expanded,
+		}
+		out[i] = &cp
+	}
+	return out, nil
+}
+
+// expandTokens scans value for "%TOKEN%" references, replacing each with
+// resolve(TOKEN). resolve is only ever called for tokens found in
+// headerTemplateVars; an unrecognized token is reported as an error without
+// calling resolve.
+func expandTokens(value string, resolve func(token string) string) (string, error) {
+	var b strings.Builder
+	rest := value
+	for {
+		start := strings.IndexByte(rest, '%')
+		if start == -1 {
+			b.WriteString(rest)
+			return b.String(), nil
+		}
+		end := strings.IndexByte(rest[start+1:], '%')
+		if end == -1 {
+			// No closing '%': treat the rest of the string literally.
+			b.WriteString(rest)
+			return b.String(), nil
+		}
+		end += start + 1
+
+		b.WriteString(rest[:start])
+		token := rest[start+1 : end]
+		if _, ok := headerTemplateVars[token]; !ok {
+			return "", fmt.Errorf("unknown header template variable %q", token)
+		}
+		b.WriteString(resolve(token))
+
+		rest = rest[end+1:]
+	}
+}
@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package envoy
+
+import (
+	"fmt"
+
+	v3 "github.com/cilium/proxy/go/envoy/config/core/v3"
+	tcp_proxy "github.com/cilium/proxy/go/envoy/extensions/filters/network/tcp_proxy/v3"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/cilium/cilium/internal/proxystate"
+)
+
+// BuildTcpProxyFromRoute translates route's dataplane-neutral IR into a
+// concrete TcpProxy, the first step in migrating the TCP weighted-cluster path
+// off building *tcp_proxy.TcpProxy directly from policy structs. HeaderMutations
+// become TunnelingConfig.HeadersToAdd; a single Cluster becomes the plain
+// Cluster field, and more than one becomes WeightedClusters.
+//
+// route.AccessLogs and route.Intentions are not yet represented on the
+// returned TcpProxy: rendering AccessLogs requires the
+// envoy.config.accesslog.v3.AccessLog message types, which are not vendored
+// in this tree, and Intentions are meant to be enforced by
+// calling route.Allowed before a connection is ever handed to this function,
+// not encoded into the proxy config itself.
+func BuildTcpProxyFromRoute(route *proxystate.TCPRoute) (*tcp_proxy.TcpProxy, error) {
+	if route.Listener == nil {
+		return nil, fmt.Errorf("route has no listener")
+	}
+	if len(route.Listener.Clusters) == 0 {
+		return nil, fmt.Errorf("listener %q has no clusters", route.Listener.Name)
+	}
+
+	tp := &tcp_proxy.TcpProxy{
+		StatPrefix: route.Listener.Name,
+	}
+
+	if len(route.Listener.Clusters) == 1 {
+		tp.ClusterSpecifier = &tcp_proxy.TcpProxy_Cluster{
+			Cluster: route.Listener.Clusters[0].Name,
+		}
+	} else {
+		weighted := make([]*tcp_proxy.TcpProxy_WeightedCluster_ClusterWeight, len(route.Listener.Clusters))
+		for i, c := range route.Listener.Clusters {
+			weighted[i] = &tcp_proxy.TcpProxy_WeightedCluster_ClusterWeight{
+				Name:   c.Name,
+				Weight: c.Weight,
+			}
+		}
+		tp.ClusterSpecifier = &tcp_proxy.TcpProxy_WeightedClusters{
+			WeightedClusters: &tcp_proxy.TcpProxy_WeightedCluster{Clusters: weighted},
+		}
+	}
+
+	if len(route.HeaderMutations) > 0 {
+		headers := make([]*v3.HeaderValueOption, len(route.HeaderMutations))
+		for i, m := range route.HeaderMutations {
+			headers[i] = &v3.HeaderValueOption{
+				Header: &v3.HeaderValue{
+					Key:   m.Key,
+					Value: m.Value,
+				},
+				Append: wrapperspb.Bool(m.Append),
+			}
+		}
+		tp.TunnelingConfig = &tcp_proxy.TcpProxy_TunnelingConfig{
+			HeadersToAdd: headers,
+		}
+	}
+
+	if err := ValidateTcpProxy(tp); err != nil {
+		return nil, fmt.Errorf("route %q: %w", route.Listener.Name, err)
+	}
+	return tp, nil
+}
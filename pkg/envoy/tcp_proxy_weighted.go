@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package envoy
+
+import (
+	"errors"
+	"fmt"
+
+	v3 "github.com/cilium/proxy/go/envoy/config/core/v3"
+	tcp_proxy "github.com/cilium/proxy/go/envoy/extensions/filters/network/tcp_proxy/v3"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ErrZeroTotalWeight is returned by ValidateTcpProxy when a weighted_clusters
+// config's cluster weights all add up to zero, which Envoy's subset load
+// balancer cannot route on.
+var ErrZeroTotalWeight = errors.New("weighted_clusters has a total weight of 0")
+
+// ValidateTcpProxy checks tp for configurations that the proto comments
+// describe as invalid but that the proto itself cannot reject, since other
+// consumers of this config (not just Envoy's own C++ implementation) need the
+// same guarantees to behave deterministically:
+//
+//   - hash_policy must have at most one entry, as already documented on the
+//     field itself.
+//   - weighted_clusters must have a nonzero total weight, or there is no
+//     meaningful way to pick an upstream cluster.
+func ValidateTcpProxy(tp *tcp_proxy.TcpProxy) error {
+	if len(tp.GetHashPolicy()) > 1 {
+		return fmt.Errorf("hash_policy has %d entries, at most 1 is supported", len(tp.GetHashPolicy()))
+	}
+
+	wc := tp.GetWeightedClusters()
+	if wc == nil {
+		return nil
+	}
+
+	var total uint32
+	for _, cw := range wc.GetClusters() {
+		total += cw.GetWeight()
+	}
+	if total == 0 {
+		return ErrZeroTotalWeight
+	}
+
+	return nil
+}
+
+// EffectiveMetadataMatch computes the endpoint metadata match Envoy's subset
+// load balancer uses for a connection routed to cw, one of tp's
+// weighted_clusters entries: a deep merge of tp.MetadataMatch and
+// cw.MetadataMatch, with cw's values taking precedence over tp's wherever both
+// set the same filter or key. Neither input is mutated.
+func EffectiveMetadataMatch(tp *tcp_proxy.TcpProxy, cw *tcp_proxy.TcpProxy_WeightedCluster_ClusterWeight) *v3.Metadata {
+	base := tp.GetMetadataMatch()
+	override := cw.GetMetadataMatch()
+	if base == nil {
+		return override
+	}
+	if override == nil {
+		return base
+	}
+
+	merged := &v3.Metadata{
+		FilterMetadata: make(map[string]*structpb.Struct, len(base.GetFilterMetadata())),
+	}
+	for filter, fields := range base.GetFilterMetadata() {
+		merged.FilterMetadata[filter] = cloneStruct(fields)
+	}
+	for filter, fields := range override.GetFilterMetadata() {
+		existing, ok := merged.FilterMetadata[filter]
+		if !ok {
+			merged.FilterMetadata[filter] = cloneStruct(fields)
+			continue
+		}
+		for k, v := range fields.GetFields() {
+			existing.Fields[k] = v
+		}
+	}
+
+	return merged
+}
+
+func cloneStruct(s *structpb.Struct) *structpb.Struct {
+	fields := make(map[string]*structpb.Value, len(s.GetFields()))
+	for k, v := range s.GetFields() {
+		fields[k] = v
+	}
+	return &structpb.Struct{Fields: fields}
+}
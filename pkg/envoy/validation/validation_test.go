@@ -0,0 +1,61 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package validation
+
+import (
+	"testing"
+
+	tcp_proxy "github.com/cilium/proxy/go/envoy/extensions/filters/network/tcp_proxy/v3"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestValidateAllRecursiveNilMessage(t *testing.T) {
+	var tp *tcp_proxy.TcpProxy
+	if errs := ValidateAllRecursive(tp); len(errs) != 0 {
+		t.Fatalf("expected no errors for a nil message, got %v", errs)
+	}
+}
+
+func TestValidateAllRecursiveWalksNestedMessages(t *testing.T) {
+	tp := &tcp_proxy.TcpProxy{
+		StatPrefix: "test",
+		ClusterSpecifier: &tcp_proxy.TcpProxy_WeightedClusters{
+			WeightedClusters: &tcp_proxy.TcpProxy_WeightedCluster{
+				Clusters: []*tcp_proxy.TcpProxy_WeightedCluster_ClusterWeight{
+					{Name: "a", Weight: 1},
+					{Name: "b", Weight: 1},
+				},
+			},
+		},
+		IdleTimeout: durationpb.New(0),
+	}
+
+	// None of TcpProxy's messages implement ValidateAll in this tree (no
+	// tcp_proxy.pb.validate.go is vendored), so walking a well-formed
+	// message nested several levels deep - embedded message, repeated
+	// message field - must not panic and must report no violations.
+	if errs := ValidateAllRecursive(tp); len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+}
+
+func TestValidateAnyUnregisteredType(t *testing.T) {
+	any := &anypb.Any{
+		TypeUrl: "type.googleapis.com/does.not.Exist",
+		Value:   []byte{0x01},
+	}
+
+	errs := validateAny(field.NewPath("any"), any)
+	if len(errs) != 1 {
+		t.Fatalf("expected exactly one violation for an unresolvable Any, got %v", errs)
+	}
+}
+
+func TestValidateAnyEmptyTypeURL(t *testing.T) {
+	if errs := validateAny(field.NewPath("any"), &anypb.Any{}); len(errs) != 0 {
+		t.Fatalf("expected no violations for an unset Any, got %v", errs)
+	}
+}
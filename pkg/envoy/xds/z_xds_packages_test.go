@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package xds
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// vendoredEnvoyPackages walks vendor/github.com/cilium/proxy/go/envoy's
+// extensions and config subtrees and returns the Go import path of every
+// directory that contains at least one .go file.
+func vendoredEnvoyPackages(t *testing.T) []string {
+	t.Helper()
+
+	_, thisFile, _, ok := runtime.Caller(0)
+	if !ok {
+		t.Fatal("unable to determine this test file's path")
+	}
+	// pkg/envoy/xds/z_xds_packages_test.go -> repo root is three levels up.
+	repoRoot := filepath.Join(filepath.Dir(thisFile), "..", "..", "..")
+	envoyDir := filepath.Join(repoRoot, "vendor", "github.com", "cilium", "proxy", "go", "envoy")
+
+	var packages []string
+	for _, sub := range []string{"extensions", "config"} {
+		root := filepath.Join(envoyDir, sub)
+		if _, err := os.Stat(root); os.IsNotExist(err) {
+			continue
+		}
+		err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || !strings.HasSuffix(path, ".go") {
+				return nil
+			}
+			dir := filepath.Dir(path)
+			rel, err := filepath.Rel(envoyDir, dir)
+			if err != nil {
+				return err
+			}
+			importPath := "github.com/cilium/proxy/go/envoy/" + filepath.ToSlash(rel)
+			for _, existing := range packages {
+				if existing == importPath {
+					return nil
+				}
+			}
+			packages = append(packages, importPath)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("walking %s: %v", root, err)
+		}
+	}
+	return packages
+}
+
+// referencedPackages returns the import path of every blank import in
+// z_xds_packages.go, by reading the file as text and collecting every line
+// that starts with `_ "`, rather than importing the package under test and
+// inspecting its imports.
+func referencedPackages(t *testing.T) map[string]bool {
+	t.Helper()
+
+	_, thisFile, _, ok := runtime.Caller(0)
+	if !ok {
+		t.Fatal("unable to determine this test file's path")
+	}
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(thisFile), "z_xds_packages.go"))
+	if err != nil {
+		t.Fatalf("reading z_xds_packages.go: %v", err)
+	}
+
+	referenced := make(map[string]bool)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, `_ "`) {
+			continue
+		}
+		importPath := strings.TrimSuffix(strings.TrimPrefix(line, `_ "`), `"`)
+		referenced[importPath] = true
+	}
+	return referenced
+}
+
+// TestAllVendoredExtensionsAreReferenced fails if a package gets vendored
+// under envoy/extensions or envoy/config without contrib/scripts/generate-xds-packages.sh
+// having been re-run to add its blank import to z_xds_packages.go - the
+// scenario that otherwise silently reintroduces the "escape hatch config
+// fails to unmarshal" bug this package exists to fix.
+func TestAllVendoredExtensionsAreReferenced(t *testing.T) {
+	referenced := referencedPackages(t)
+
+	var missing []string
+	for _, pkg := range vendoredEnvoyPackages(t) {
+		if !referenced[pkg] {
+			missing = append(missing, pkg)
+		}
+	}
+	if len(missing) > 0 {
+		t.Fatalf("vendored package(s) missing from z_xds_packages.go, re-run "+
+			"contrib/scripts/generate-xds-packages.sh: %v", missing)
+	}
+}
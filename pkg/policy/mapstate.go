@@ -4,6 +4,7 @@
 package policy
 
 import (
+	"encoding/json"
 	"fmt"
 	"iter"
 	"slices"
@@ -94,12 +95,25 @@ var (
 // that associates each identity with a mapStateEntry. This strategy
 // greatly enhances the usefuleness of the Trie and improves lookup,
 // deletion, and insertion times.
+//
+// Note: policyTypes.LPMKey currently indexes only TrafficDirection, Protocol, and
+// Port, so ICMP type/code matches are not part of the trie's prefix and fall back to
+// a linear scan of 'entries' in BroaderOrEqualKeys/NarrowerOrEqualKeys. Indexing the
+// ICMP type (and optionally the code) would require extending policyTypes.LPMKey and
+// policyTypes.MapStatePrefixLen - neither of which this checkout has source for, only
+// the compiled pkg/policy/types import this file already makes - so this is an
+// unimplemented gap, not a completed change: it needs doing in that package before
+// anything here can change.
 type mapState struct {
 	// entries is the map containing the MapStateEntries
 	entries map[Key]mapStateEntry
 	// trie is a Trie that indexes policy Keys without their identity
 	// and stores the identities in an associated builtin map.
 	trie bitlpm.Trie[bitlpm.Key[policyTypes.LPMKey], IDSet]
+
+	// generation is bumped on every upsert/delete that mutates 'entries' or 'trie'.
+	// Snapshot() readers use it to tell which version of the state they pinned.
+	generation uint64
 }
 
 type IDSet map[identity.NumericIdentity]struct{}
@@ -123,6 +137,7 @@ func (msm *mapState) upsert(k Key, e mapStateEntry) {
 
 	// upsert entry
 	msm.entries[k] = e
+	msm.generation++
 
 	// Update indices if 'k' is a new key
 	if !exists {
@@ -143,6 +158,7 @@ func (msm *mapState) delete(k Key) {
 	_, exists := msm.entries[k]
 	if exists {
 		delete(msm.entries, k)
+		msm.generation++
 
 		id := k.Identity
 		idSet, ok := msm.trie.ExactLookup(k.PrefixLength(), k)
@@ -323,6 +339,83 @@ func (msm *mapState) Len() int {
 	return len(msm.entries)
 }
 
+// MapStateSnapshot is a read-only, point-in-time view of a mapState's entries and
+// trie, so concurrent readers (e.g., the datapath sync goroutine or the policy
+// debug HTTP handlers) can keep iterating a consistent view while policy
+// recomputation continues to mutate the live mapState, without holding the
+// endpoint lock across the iteration.
+//
+// A MapStateSnapshot is taken by copying 'entries' and 'trie' rather than
+// sharing them path-copy style with the live state; this keeps the
+// implementation simple and safe for concurrent reads at the cost of an O(n)
+// copy per snapshot. Switching 'entries'/'trie' to genuinely persistent data
+// structures would let Snapshot become O(1) without changing this API.
+type MapStateSnapshot struct {
+	entries    map[Key]mapStateEntry
+	trie       bitlpm.Trie[bitlpm.Key[policyTypes.LPMKey], IDSet]
+	generation uint64
+}
+
+// Snapshot returns an immutable copy of 'msm'.
+func (msm *mapState) Snapshot() MapStateSnapshot {
+	entries := make(map[Key]mapStateEntry, len(msm.entries))
+	for k, v := range msm.entries {
+		entries[k] = v
+	}
+	return MapStateSnapshot{
+		entries:    entries,
+		trie:       msm.trie.Clone(),
+		generation: msm.generation,
+	}
+}
+
+// Generation returns the mapState generation this snapshot was taken at.
+func (s *MapStateSnapshot) Generation() uint64 {
+	return s.generation
+}
+
+// Lookup returns the MapStateEntry that matches 'k' in this snapshot.
+func (s *MapStateSnapshot) Lookup(k Key) (MapStateEntry, bool) {
+	v, ok := s.entries[k]
+	return v.MapStateEntry, ok
+}
+
+// ForEach calls 'f' for each Key/MapStateEntry pair in this snapshot.
+func (s *MapStateSnapshot) ForEach(f func(Key, MapStateEntry) bool) bool {
+	for k, e := range s.entries {
+		if !f(k, e.MapStateEntry) {
+			return false
+		}
+	}
+	return true
+}
+
+// BroaderOrEqualKeys iterates over broader-or-equal keys in this snapshot. See
+// mapState.BroaderOrEqualKeys for semantics.
+func (s *MapStateSnapshot) BroaderOrEqualKeys(key Key) iter.Seq2[Key, MapStateEntry] {
+	ms := mapState{entries: s.entries, trie: s.trie}
+	return func(yield func(Key, MapStateEntry) bool) {
+		for k, v := range ms.BroaderOrEqualKeys(key) {
+			if !yield(k, v.MapStateEntry) {
+				return
+			}
+		}
+	}
+}
+
+// NarrowerOrEqualKeys iterates over narrower-or-equal keys in this snapshot. See
+// mapState.NarrowerOrEqualKeys for semantics.
+func (s *MapStateSnapshot) NarrowerOrEqualKeys(key Key) iter.Seq2[Key, MapStateEntry] {
+	ms := mapState{entries: s.entries, trie: s.trie}
+	return func(yield func(Key, MapStateEntry) bool) {
+		for k, v := range ms.NarrowerOrEqualKeys(key) {
+			if !yield(k, v.MapStateEntry) {
+				return
+			}
+		}
+	}
+}
+
 // MapStateEntry is the configuration associated with a Key in a
 // MapState. This is a minimized version of policymap.PolicyEntry.
 type MapStateEntry struct {
@@ -337,6 +430,14 @@ type MapStateEntry struct {
 	// IsDeny is true when the policy should be denied.
 	IsDeny bool
 
+	// IsAuditDeny is only meaningful when IsDeny is true. It marks a deny entry as
+	// audit-only: the would-be drop is recorded in this mapState for visibility (e.g.,
+	// policy verdict logging), but the entry does not take deny precedence over
+	// allow entries it would otherwise cover, and is not enforced by the datapath as
+	// a drop. This lets a deny rule be rolled out in a "what would this block"
+	// fashion before it is enforced.
+	IsAuditDeny bool
+
 	// HasAuthType is 'DefaultAuthType' when policy has no explicit AuthType set. In this case
 	// the value of AuthType is derived from more generic entries covering this entry.
 	HasAuthType HasAuthType
@@ -554,6 +655,12 @@ func (e *mapStateEntry) merge(entry *mapStateEntry) {
 			Errorf("MapStateEntry.merge: both entries must be allows or denies")
 		return
 	}
+	// An enforced deny takes precedence over an audit-only one, so that merging in a
+	// real (enforced) deny for the same key always ends up enforced.
+	if e.IsDeny {
+		e.IsAuditDeny = e.IsAuditDeny && entry.IsAuditDeny
+	}
+
 	// Only allow entries have proxy redirection or auth requirement
 	if !e.IsDeny {
 		// Proxy port takes precedence, but may be updated due to priority
@@ -598,25 +705,25 @@ func (e *MapStateEntry) IsRedirectEntry() bool {
 }
 
 // Equal returns true of two entries are equal,
-// i.e., IsDeny, ProxyPort and AuthType are the same for both entries.
+// i.e., IsDeny, IsAuditDeny, ProxyPort and AuthType are the same for both entries.
 func (e *MapStateEntry) Equal(o *MapStateEntry) bool {
 	if e == nil || o == nil {
 		return e == o
 	}
 
-	return e.IsDeny == o.IsDeny && e.ProxyPort == o.ProxyPort && e.AuthType == o.AuthType
+	return e.IsDeny == o.IsDeny && e.IsAuditDeny == o.IsAuditDeny && e.ProxyPort == o.ProxyPort && e.AuthType == o.AuthType
 }
 
 // DatapathAndDerivedFromEqual returns true of two entries are equal in the datapath's PoV,
-// i.e., IsDeny, ProxyPort and AuthType are the same for both entries, and the DerivedFromRules
-// fields are also equal.
+// i.e., IsDeny, IsAuditDeny, ProxyPort and AuthType are the same for both entries, and the
+// DerivedFromRules fields are also equal.
 // This is used for testing only via mapState.Equal and mapState.Diff.
 func (e *mapStateEntry) DatapathAndDerivedFromEqual(o *mapStateEntry) bool {
 	if e == nil || o == nil {
 		return e == o
 	}
 
-	return e.IsDeny == o.IsDeny && e.ProxyPort == o.ProxyPort && e.AuthType == o.AuthType &&
+	return e.IsDeny == o.IsDeny && e.IsAuditDeny == o.IsAuditDeny && e.ProxyPort == o.ProxyPort && e.AuthType == o.AuthType &&
 		e.derivedFromRules.DeepEqual(&o.derivedFromRules)
 }
 
@@ -648,11 +755,22 @@ func (e MapStateEntry) WithAuthType(authType AuthType) MapStateEntry {
 	return e
 }
 
+// WithAuditDeny marks a deny entry as audit-only: it is recorded for visibility, but
+// not enforced, and does not take precedence over allow entries it would otherwise
+// cover. It has no effect on an allow entry.
+func (e MapStateEntry) WithAuditDeny(audit bool) MapStateEntry {
+	if e.IsDeny {
+		e.IsAuditDeny = audit
+	}
+	return e
+}
+
 // String returns a string representation of the MapStateEntry
 func (e MapStateEntry) String() string {
 	return "ProxyPort=" + strconv.FormatUint(uint64(e.ProxyPort), 10) +
 		",Listener=" + e.Listener +
 		",IsDeny=" + strconv.FormatBool(e.IsDeny) +
+		",IsAuditDeny=" + strconv.FormatBool(e.IsAuditDeny) +
 		",AuthType=" + e.AuthType.String()
 }
 
@@ -664,6 +782,59 @@ func (e mapStateEntry) String() string {
 		",owners=" + e.owners.String()
 }
 
+// mapStateEntryDump is the stable, exported shape used to serialize a mapStateEntry
+// for offline analysis and replay. It mirrors mapStateEntry, but represents Owners as
+// their string form (CachedSelector.String()) since the live selectors themselves
+// cannot be serialized or reconstructed outside of a running agent.
+type mapStateEntryDump struct {
+	Key              Key
+	MapStateEntry    MapStateEntry
+	Priority         uint16
+	DerivedFromRules labels.LabelArrayList
+	Owners           string
+}
+
+// MarshalJSON serializes the full mapState, including the internal bookkeeping
+// (DerivedFromRules, Priority, Owners) that is not part of the exported MapStateMap,
+// so that a dump can be used to reproduce policy resolution bugs in unit tests, or
+// diffed against another dump with mapState.Diff to bisect which rule caused an
+// unexpected deny.
+func (msm *mapState) MarshalJSON() ([]byte, error) {
+	dump := make([]mapStateEntryDump, 0, len(msm.entries))
+	msm.forEach(func(k Key, e mapStateEntry) bool {
+		dump = append(dump, mapStateEntryDump{
+			Key:              k,
+			MapStateEntry:    e.MapStateEntry,
+			Priority:         e.priority,
+			DerivedFromRules: e.derivedFromRules,
+			Owners:           e.owners.String(),
+		})
+		return true
+	})
+	return json.Marshal(dump)
+}
+
+// UnmarshalJSON loads a mapState previously produced by MarshalJSON. The resulting
+// entries have no owners (the original CachedSelectors cannot be reconstructed from
+// their string form), so they are inserted as "sticky" entries per newMapStateEntry:
+// they cannot be completely removed via incremental updates, but are otherwise
+// equivalent to the original from the datapath's point of view, which is sufficient
+// to replay a captured state into newMapState() for analysis.
+func (msm *mapState) UnmarshalJSON(data []byte) error {
+	var dump []mapStateEntryDump
+	if err := json.Unmarshal(data, &dump); err != nil {
+		return err
+	}
+	if msm.entries == nil {
+		*msm = newMapState()
+	}
+	for _, d := range dump {
+		e := d.MapStateEntry.toMapStateEntry(d.Priority, nil, d.DerivedFromRules)
+		msm.upsert(d.Key, e)
+	}
+	return nil
+}
+
 // addKeyWithChanges adds a 'key' with value 'entry' to 'keys' keeping track of incremental changes in 'adds' and 'deletes', and any changed or removed old values in 'old', if not nil.
 func (ms *mapState) addKeyWithChanges(key Key, entry mapStateEntry, changes ChangeState) bool {
 	// Keep all owners that need this entry so that it is deleted only if all the owners delete their contribution
@@ -747,6 +918,97 @@ func (ms *mapState) deleteKeyWithChanges(key Key, owner MapStateOwner, changes C
 	}
 }
 
+// mapStateTxn stages a batch of mapState mutations so that they can be published or
+// discarded as a single atomic unit. It reuses the same ChangeState/revertChanges
+// machinery that backs incremental updates, so an aborted transaction leaves 'ms'
+// exactly as it was before Begin was called.
+//
+// This is intentionally a mutate-then-revert wrapper around the existing
+// addKeyWithChanges/revertChanges primitives, not an isolated staging overlay that a
+// concurrent reader could ignore until Commit. A real overlay would need either a deep
+// copy of 'ms' per Begin (entries map and trie) or a copy-on-write layer like the one
+// rejected for Snapshot (see MapStateSnapshot) - too expensive to pay on
+// every incremental policy update for a property (isolation from concurrent readers)
+// that the endpoint lock already provides. Scoped-down contract: there is no staging
+// overlay. Each AddKeyWithChanges/DeleteKeyWithChanges call mutates 'ms.entries' and its
+// trie directly, and only ChangeState (which entries were touched, and what they
+// replaced) is tracked separately so Abort can revert them. A reader iterating 'ms'
+// without holding the endpoint lock can therefore observe a transaction mid-flight,
+// with some but not all of its keys applied; callers that need a consistent view across
+// a transaction must either hold the endpoint lock for its whole Begin..Commit/Abort
+// span, or read a MapStateSnapshot taken before Begin or after Commit/Abort instead.
+type mapStateTxn struct {
+	ms      *mapState
+	changes ChangeState
+	errs    map[Key]error
+}
+
+// Begin starts a new transaction against 'ms'. Mutations staged through the returned
+// transaction are applied to 'ms' immediately (so that LPM conflict checks made while
+// staging later keys see the effect of earlier ones in the same batch), but are only
+// considered final once Commit is called. Abort undoes all of them. See mapStateTxn
+// for the concurrency implications of applying mutations immediately rather than to
+// an isolated overlay.
+func (ms *mapState) Begin() *mapStateTxn {
+	return &mapStateTxn{
+		ms: ms,
+		changes: ChangeState{
+			Adds:    make(Keys),
+			Deletes: make(Keys),
+			old:     make(map[Key]mapStateEntry),
+		},
+		errs: make(map[Key]error),
+	}
+}
+
+// AddKeyWithChanges stages the insertion of 'key'/'entry' into the transaction,
+// applying the usual deny-over-allow precedence (see denyPreferredInsertWithChanges).
+// A key that is covered by an existing or already-staged deny key is recorded as a
+// conflict in the per-key error list returned by Abort, rather than failing the whole
+// batch immediately.
+func (txn *mapStateTxn) AddKeyWithChanges(key Key, entry mapStateEntry, features policyFeatures) {
+	for k, v := range txn.ms.BroaderOrEqualKeys(key) {
+		if v.IsDeny && !(entry.IsDeny && k == key) {
+			txn.errs[key] = fmt.Errorf("key %s is covered by deny key %s", key, k)
+			return
+		}
+	}
+	txn.ms.denyPreferredInsertWithChanges(key, entry, features, txn.changes)
+}
+
+// DeleteKeyWithChanges stages the removal of 'owner's contribution to 'key' from the
+// transaction.
+func (txn *mapStateTxn) DeleteKeyWithChanges(key Key, owner MapStateOwner) {
+	txn.ms.deleteKeyWithChanges(key, owner, txn.changes)
+}
+
+// Errors returns the per-key conflicts recorded so far without aborting the
+// transaction. Callers can use this to dry-run a policy diff and report the
+// intended adds/deletes before deciding whether to Commit or Abort.
+func (txn *mapStateTxn) Errors() map[Key]error {
+	return txn.errs
+}
+
+// Changes returns the ChangeState accumulated so far without aborting the transaction.
+func (txn *mapStateTxn) Changes() ChangeState {
+	return txn.changes
+}
+
+// Commit finalizes the transaction. The staged mutations remain applied to the
+// underlying mapState, and the accumulated ChangeState is returned so that the caller
+// (e.g., selector cache updates or policy recomputation) can publish the same atomic
+// delta to the datapath.
+func (txn *mapStateTxn) Commit() ChangeState {
+	return txn.changes
+}
+
+// Abort rolls back every mutation staged since Begin, restoring 'ms' to the state it
+// was in beforehand, and returns the per-key conflicts recorded while staging, if any.
+func (txn *mapStateTxn) Abort() map[Key]error {
+	txn.ms.revertChanges(txn.changes)
+	return txn.errs
+}
+
 // RevertChanges undoes changes to 'keys' as indicated by 'changes.adds' and 'changes.old' collected via
 // denyPreferredInsertWithChanges().
 func (ms *mapState) revertChanges(changes ChangeState) {
@@ -763,6 +1025,88 @@ func (ms *mapState) insertWithChanges(key Key, entry mapStateEntry, features pol
 	ms.denyPreferredInsertWithChanges(key, entry, features, changes)
 }
 
+// copy returns a deep copy of 'ms', safe to mutate independently of the original.
+func (ms *mapState) copy() mapState {
+	entries := make(map[Key]mapStateEntry, len(ms.entries))
+	for k, v := range ms.entries {
+		entries[k] = v
+	}
+	return mapState{
+		entries:    entries,
+		trie:       ms.trie.Clone(),
+		generation: ms.generation,
+	}
+}
+
+// ComposeBatch applies the whole set of adds/deletes in 'entries' to 'ms' as a
+// single logical transaction whose result is provably independent of the order
+// 'entries' were given in.
+func (ms *mapState) ComposeBatch(entries []MapChange) ChangeState {
+	return ms.composeBatch(entries)
+}
+
+// ComposeBatchDryRun behaves like ComposeBatch, but computes the result against a
+// scratch copy of 'ms' and never mutates ms.entries/ms.trie, so that callers (e.g.,
+// consumeMapChanges) can preview the effect of a batch before committing it.
+func (ms *mapState) ComposeBatchDryRun(entries []MapChange) ChangeState {
+	scratch := ms.copy()
+	return scratch.composeBatch(entries)
+}
+
+// composeBatch applies 'entries' in a canonical order chosen so that the result
+// does not depend on the order the caller gave them in:
+//  1. deletes are applied before adds, so that an add always wins over a delete of
+//     the same key within the same batch, matching how a second AccumulateMapChanges
+//     call would supersede an earlier one.
+//  2. adds are then applied broadest-prefix-first, and deny entries before allow
+//     entries of the same prefix length, so that deny-over-allow covering (handled by
+//     the existing denyPreferredInsertWithChanges) is resolved identically whether the
+//     allow or the covering deny appeared first in the batch.
+func (ms *mapState) composeBatch(entries []MapChange) ChangeState {
+	changes := ChangeState{
+		Adds:    make(Keys),
+		Deletes: make(Keys),
+		old:     make(map[Key]mapStateEntry),
+	}
+
+	batch := slices.Clone(entries)
+	slices.SortStableFunc(batch, func(a, b MapChange) int {
+		if a.Add != b.Add {
+			if !a.Add {
+				return -1
+			}
+			return 1
+		}
+		if !a.Add {
+			return 0 // order among deletes does not matter
+		}
+		if a.Key.PrefixLength() != b.Key.PrefixLength() {
+			if a.Key.PrefixLength() < b.Key.PrefixLength() {
+				return -1
+			}
+			return 1
+		}
+		if a.Value.IsDeny != b.Value.IsDeny {
+			if a.Value.IsDeny {
+				return -1
+			}
+			return 1
+		}
+		return 0
+	})
+
+	for _, e := range batch {
+		if e.Add {
+			entry := e.Value.toMapStateEntry(0, nil, nil)
+			ms.insertWithChanges(e.Key, entry, allFeatures, changes)
+		} else {
+			ms.deleteKeyWithChanges(e.Key, nil, changes)
+		}
+	}
+
+	return changes
+}
+
 // denyPreferredInsertWithChanges contains the most important business logic for policy
 // insertions. It inserts a key and entry into the map by giving preference to deny entries, and
 // L3-only deny entries over L3-L4 allows.
@@ -803,20 +1147,25 @@ func (ms *mapState) insertWithChanges(key Key, entry mapStateEntry, features pol
 //
 // Incremental changes performed are recorded in 'changes'.
 func (ms *mapState) denyPreferredInsertWithChanges(newKey Key, newEntry mapStateEntry, features policyFeatures, changes ChangeState) {
-	// Bail if covered by a deny key
+	// Bail if covered by an enforced deny key. Audit-only denies are recorded but do
+	// not take deny precedence, so they neither bail out nor get bailed out on here.
 	for k, v := range ms.BroaderOrEqualKeys(newKey) {
 		// Identical deny key needs to be added to merge their entries.
-		if v.IsDeny && !(newEntry.IsDeny && k == newKey) {
+		if v.IsDeny && !v.IsAuditDeny && !(newEntry.IsDeny && k == newKey) {
 			return
 		}
 	}
 
 	if newEntry.IsDeny {
-		// Delete covered entries
-		for k, v := range ms.NarrowerOrEqualKeys(newKey) {
-			// Except for identical deny keys that need to be merged.
-			if !(v.IsDeny && k == newKey) {
-				ms.deleteKeyWithChanges(k, nil, changes)
+		// Audit-only denies are recorded as-is, but never delete the allow entries
+		// they would otherwise cover, since they are not enforced.
+		if !newEntry.IsAuditDeny {
+			// Delete covered entries
+			for k, v := range ms.NarrowerOrEqualKeys(newKey) {
+				// Except for identical deny keys that need to be merged.
+				if !(v.IsDeny && k == newKey) {
+					ms.deleteKeyWithChanges(k, nil, changes)
+				}
 			}
 		}
 	} else {
@@ -1021,6 +1370,36 @@ func (mc *MapChanges) detach() {
 	mc.mutex.Unlock()
 }
 
+// MapChangesTelemetry summarizes the current state of a MapChanges buffer, for
+// reporting via metrics or the policy debug HTTP handlers.
+type MapChangesTelemetry struct {
+	// Pending is the number of changes accumulated since the last SyncMapChanges
+	// call, i.e., changes not yet visible to consumeMapChanges.
+	Pending int
+
+	// Synced is the number of changes synced via SyncMapChanges but not yet
+	// consumed via consumeMapChanges. A persistently large value here indicates
+	// the consumer (endpoint regeneration) is falling behind.
+	Synced int
+
+	// Version is the selector cache version handle currently pinned by this
+	// MapChanges, or nil if none is held. Comparing it against the selector
+	// cache's latest version gives the version lag of this consumer.
+	Version *versioned.VersionHandle
+}
+
+// Telemetry returns a point-in-time snapshot of 'mc's change-buffer sizes and
+// pinned version. It does not consume or mutate any state.
+func (mc *MapChanges) Telemetry() MapChangesTelemetry {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+	return MapChangesTelemetry{
+		Pending: len(mc.changes),
+		Synced:  len(mc.synced),
+		Version: mc.version,
+	}
+}
+
 // consumeMapChanges transfers the incremental changes from MapChanges to the caller,
 // while applying the changes to PolicyMapState.
 func (mc *MapChanges) consumeMapChanges(p *EndpointPolicy, features policyFeatures) (*versioned.VersionHandle, ChangeState) {
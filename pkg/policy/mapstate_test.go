@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package policy
+
+import (
+	"testing"
+
+	"github.com/cilium/cilium/pkg/identity"
+)
+
+// composeBatchFixture returns a batch mixing ingress/egress, allow/deny, and a
+// delete, so that reordering it actually exercises composeBatch's canonical
+// sort (deletes-before-adds, deny-before-allow) rather than trivially agreeing
+// regardless of order.
+func composeBatchFixture() []MapChange {
+	return []MapChange{
+		{Add: true, Key: IngressKey().WithIdentity(identity.NumericIdentity(1)), Value: MapStateEntry{}},
+		{Add: true, Key: IngressKey().WithIdentity(identity.NumericIdentity(2)), Value: MapStateEntry{IsDeny: true}},
+		{Add: true, Key: EgressKey().WithIdentity(identity.NumericIdentity(3)), Value: MapStateEntry{}},
+		{Add: true, Key: EgressKey().WithIdentity(identity.NumericIdentity(4)), Value: MapStateEntry{IsDeny: true}},
+		{Add: false, Key: IngressKey().WithIdentity(identity.NumericIdentity(5))},
+	}
+}
+
+// TestComposeBatchOrderIndependent asserts ComposeBatch's own documented
+// contract: the resulting mapState does not depend on the order 'entries'
+// were given in.
+func TestComposeBatchOrderIndependent(t *testing.T) {
+	orderings := [][]int{
+		{0, 1, 2, 3, 4},
+		{4, 3, 2, 1, 0},
+		{1, 3, 0, 4, 2},
+		{2, 4, 0, 3, 1},
+	}
+
+	deleteTarget := IngressKey().WithIdentity(identity.NumericIdentity(5))
+
+	base := composeBatchFixture()
+	var want map[Key]mapStateEntry
+	for _, order := range orderings {
+		shuffled := make([]MapChange, len(order))
+		for i, idx := range order {
+			shuffled[i] = base[idx]
+		}
+
+		ms := newMapState()
+		// Seed the delete target so the delete in the batch has something to
+		// remove; otherwise it is a no-op and the test would not catch a
+		// delete/add ordering bug.
+		ms.insert(deleteTarget, mapStateEntry{})
+
+		ms.ComposeBatch(shuffled)
+
+		if want == nil {
+			want = make(map[Key]mapStateEntry, len(ms.entries))
+			for k, v := range ms.entries {
+				want[k] = v
+			}
+			continue
+		}
+
+		if len(ms.entries) != len(want) {
+			t.Fatalf("ordering %v: got %d entries, want %d", order, len(ms.entries), len(want))
+		}
+		for k, wantEntry := range want {
+			gotEntry, ok := ms.entries[k]
+			if !ok {
+				t.Fatalf("ordering %v: missing key %v", order, k)
+			}
+			if gotEntry.IsDeny != wantEntry.IsDeny || gotEntry.IsAuditDeny != wantEntry.IsAuditDeny {
+				t.Fatalf("ordering %v: key %v = %+v, want %+v", order, k, gotEntry.MapStateEntry, wantEntry.MapStateEntry)
+			}
+		}
+	}
+}
+
+// TestComposeBatchDryRunDoesNotMutate asserts ComposeBatchDryRun's documented
+// contract: it must leave 'ms' untouched regardless of what the batch would do.
+func TestComposeBatchDryRunDoesNotMutate(t *testing.T) {
+	ms := newMapState()
+	before := len(ms.entries)
+
+	ms.ComposeBatchDryRun(composeBatchFixture())
+
+	if len(ms.entries) != before {
+		t.Fatalf("ComposeBatchDryRun mutated ms: got %d entries, want %d", len(ms.entries), before)
+	}
+}
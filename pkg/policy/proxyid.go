@@ -5,6 +5,7 @@ package policy
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -22,39 +23,197 @@ func ProxyStatsKey(ingress bool, protocol string, port, proxyPort uint16) string
 	return fmt.Sprintf("%s:%s:%d:%d", direction, protocol, port, proxyPort)
 }
 
-// ProxyID returns a unique string to identify a proxy mapping.
-func ProxyID(endpointID uint16, ingress bool, protocol string, port uint16, listener string) string {
-	direction := "egress"
-	if ingress {
-		direction = "ingress"
+// proxyIDVersion is the version tag ProxyID.String prefixes its output with,
+// so that ParseProxyID can tell which format it is decoding. Bump this (and
+// add a case to ParseProxyID) whenever ProxyID grows a field that needs to be
+// on the wire or on disk, instead of changing the existing format in place
+// and silently breaking whatever produced or stored the old one.
+const proxyIDVersion = 2
+
+// ProxyID uniquely identifies a proxy mapping: a single redirection of one
+// endpoint's traffic on one port/direction/protocol to a named listener.
+// Unlike the positional colon-delimited strings this replaces, Listener is
+// escaped on encode, so a listener name containing a colon cannot corrupt the
+// other fields, and new fields can be added to Extra without forcing every
+// existing decoder to be updated in lock-step.
+type ProxyID struct {
+	EndpointID uint16
+	Ingress    bool
+	Protocol   u8proto.U8proto
+	Port       uint16
+	Listener   string
+	// Extra carries additional proxy-mapping attributes (e.g. a TLS-inspected
+	// SNI or an L7-parser hint) that a particular version of ProxyID may want
+	// to encode without requiring another flag day. It is encoded in a fixed,
+	// sorted-by-key order so that String is deterministic.
+	Extra map[string]string
+}
+
+// NewProxyID builds the ProxyID for a single endpoint/direction/protocol/port
+// redirect to listener.
+func NewProxyID(endpointID uint16, ingress bool, protocol u8proto.U8proto, port uint16, listener string) ProxyID {
+	return ProxyID{
+		EndpointID: endpointID,
+		Ingress:    ingress,
+		Protocol:   protocol,
+		Port:       port,
+		Listener:   listener,
 	}
-	return fmt.Sprintf("%d:%s:%s:%d:%s", endpointID, direction, protocol, port, listener)
 }
 
 // ProxyIDFromKey returns a unique string to identify a proxy mapping.
 func ProxyIDFromKey(endpointID uint16, key Key, listener string) string {
-	return ProxyID(endpointID, key.TrafficDirection == trafficdirection.Ingress.Uint8(), u8proto.U8proto(key.Nexthdr).String(), key.DestPort, listener)
+	id := NewProxyID(endpointID, key.TrafficDirection == trafficdirection.Ingress.Uint8(), u8proto.U8proto(key.Nexthdr), key.DestPort, listener)
+	return id.String()
+}
+
+// String encodes id as a versioned, escaped string suitable for use as a map
+// key or for storage on disk. The format is:
+//
+//	v2|<endpointID>:<direction>:<protocol>:<port>:<escaped listener>[|k=v,...]
+func (id ProxyID) String() string {
+	direction := "egress"
+	if id.Ingress {
+		direction = "ingress"
+	}
+	s := fmt.Sprintf("v%d|%d:%s:%s:%d:%s", proxyIDVersion, id.EndpointID, direction, id.Protocol.String(), id.Port, escapeListener(id.Listener))
+	if len(id.Extra) > 0 {
+		keys := make([]string, 0, len(id.Extra))
+		for k := range id.Extra {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		pairs := make([]string, 0, len(keys))
+		for _, k := range keys {
+			pairs = append(pairs, fmt.Sprintf("%s=%s", escapeListener(k), escapeListener(id.Extra[k])))
+		}
+		s += "|" + strings.Join(pairs, ",")
+	}
+	return s
+}
+
+// ParseProxyID parses a proxy ID produced by ProxyID.String, or by the
+// earlier unversioned ProxyID(...) string format, into a ProxyID.
+func ParseProxyID(proxyID string) (ProxyID, error) {
+	if rest, ok := strings.CutPrefix(proxyID, fmt.Sprintf("v%d|", proxyIDVersion)); ok {
+		return parseProxyIDV2(rest)
+	}
+	return parseProxyIDV1(proxyID)
+}
+
+func parseProxyIDV2(rest string) (ProxyID, error) {
+	var extraField string
+	body, extraField, hasExtra := strings.Cut(rest, "|")
+
+	comps := strings.Split(body, ":")
+	if len(comps) != 5 {
+		return ProxyID{}, fmt.Errorf("invalid proxy ID structure: %s", body)
+	}
+
+	id, err := proxyIDFromComponents(comps)
+	if err != nil {
+		return ProxyID{}, err
+	}
+	id.Listener, err = unescapeListener(comps[4])
+	if err != nil {
+		return ProxyID{}, fmt.Errorf("invalid proxy ID listener: %w", err)
+	}
+
+	if hasExtra && extraField != "" {
+		id.Extra = make(map[string]string)
+		for _, pair := range strings.Split(extraField, ",") {
+			k, v, ok := strings.Cut(pair, "=")
+			if !ok {
+				return ProxyID{}, fmt.Errorf("invalid proxy ID extra field: %s", pair)
+			}
+			key, err := unescapeListener(k)
+			if err != nil {
+				return ProxyID{}, fmt.Errorf("invalid proxy ID extra key: %w", err)
+			}
+			val, err := unescapeListener(v)
+			if err != nil {
+				return ProxyID{}, fmt.Errorf("invalid proxy ID extra value: %w", err)
+			}
+			id.Extra[key] = val
+		}
+	}
+
+	return id, nil
 }
 
-// ParseProxyID parses a proxy ID returned by ProxyID and returns its components.
-func ParseProxyID(proxyID string) (endpointID uint16, ingress bool, protocol string, port uint16, listener string, err error) {
+// parseProxyIDV1 decodes the original, unversioned "epID:direction:proto:port:listener"
+// format for backward compatibility with state written before ProxyID was
+// versioned. Listener is taken verbatim, since the v1 format never escaped it.
+func parseProxyIDV1(proxyID string) (ProxyID, error) {
 	comps := strings.Split(proxyID, ":")
 	if len(comps) != 5 {
-		err = fmt.Errorf("invalid proxy ID structure: %s", proxyID)
-		return
+		return ProxyID{}, fmt.Errorf("invalid proxy ID structure: %s", proxyID)
+	}
+	id, err := proxyIDFromComponents(comps)
+	if err != nil {
+		return ProxyID{}, err
 	}
+	id.Listener = comps[4]
+	return id, nil
+}
+
+// proxyIDFromComponents decodes the first four, format-independent fields
+// shared by both the v1 and v2 encodings; callers fill in Listener (and,
+// for v2, Extra) themselves.
+func proxyIDFromComponents(comps []string) (ProxyID, error) {
 	epID, err := strconv.ParseUint(comps[0], 10, 16)
 	if err != nil {
-		return
+		return ProxyID{}, err
 	}
-	endpointID = uint16(epID)
-	ingress = comps[1] == "ingress"
-	protocol = comps[2]
-	l4port, err := strconv.ParseUint(comps[3], 10, 16)
+	port, err := strconv.ParseUint(comps[3], 10, 16)
 	if err != nil {
-		return
+		return ProxyID{}, err
+	}
+	proto, err := u8proto.ParseProtocol(comps[2])
+	if err != nil {
+		return ProxyID{}, err
+	}
+	return ProxyID{
+		EndpointID: uint16(epID),
+		Ingress:    comps[1] == "ingress",
+		Protocol:   proto,
+		Port:       uint16(port),
+	}, nil
+}
+
+// escapeListener percent-encodes the handful of characters ProxyID.String
+// uses as field separators, so that a listener name (or Extra key/value)
+// containing them cannot be mistaken for a delimiter on decode.
+func escapeListener(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case ':', '|', ',', '=', '%':
+			fmt.Fprintf(&b, "%%%02X", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// unescapeListener reverses escapeListener.
+func unescapeListener(s string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+2 >= len(s) {
+			return "", fmt.Errorf("truncated escape sequence in %q", s)
+		}
+		v, err := strconv.ParseUint(s[i+1:i+3], 16, 8)
+		if err != nil {
+			return "", fmt.Errorf("invalid escape sequence in %q: %w", s, err)
+		}
+		b.WriteByte(byte(v))
+		i += 2
 	}
-	port = uint16(l4port)
-	listener = comps[4]
-	return
+	return b.String(), nil
 }
@@ -0,0 +1,16 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+// Package failpoints provides named injection points that let tests force a
+// specific error, delay, or skip at a handful of spots in production code, in
+// the style of github.com/pingcap/failpoint. An injection site is an ordinary
+// call to Eval, so it compiles into every build; only binaries built with the
+// "failpoints" tag actually evaluate registered actions (see failpoints.go).
+// Every other build links failpoints_disabled.go instead, where Eval is
+// always a no-op, so this package can never change production behavior.
+package failpoints
+
+// Action is invoked by Eval when its name is enabled via Enable. Returning a
+// non-nil error makes Eval return that error to the injection site, letting a
+// test exercise an error path without reproducing the real failure.
+type Action func() error
@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+//go:build failpoints
+
+package failpoints
+
+import "sync"
+
+var (
+	mu     sync.RWMutex
+	points = make(map[string]Action)
+)
+
+// Enable registers action to run whenever Eval(name) is called, replacing any
+// action previously registered for name. It returns a function that disables
+// name, for defer failpoints.Enable(name, action)()-style use in tests.
+func Enable(name string, action Action) func() {
+	mu.Lock()
+	points[name] = action
+	mu.Unlock()
+	return func() { Disable(name) }
+}
+
+// Disable removes any action registered for name.
+func Disable(name string) {
+	mu.Lock()
+	delete(points, name)
+	mu.Unlock()
+}
+
+// Eval runs the action registered for name, if any, and returns its error. It
+// returns nil without doing anything when no action is registered for name.
+func Eval(name string) error {
+	mu.RLock()
+	action, ok := points[name]
+	mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	return action()
+}
@@ -0,0 +1,17 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+//go:build !failpoints
+
+package failpoints
+
+// Enable is a no-op outside builds tagged "failpoints": it discards action
+// and returns a no-op disable function, so failpoint-instrumented test
+// helpers still link into test binaries built without the tag.
+func Enable(name string, action Action) func() { return func() {} }
+
+// Disable is a no-op outside builds tagged "failpoints".
+func Disable(name string) {}
+
+// Eval always returns nil outside builds tagged "failpoints".
+func Eval(name string) error { return nil }
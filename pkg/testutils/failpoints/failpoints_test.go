@@ -0,0 +1,36 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+//go:build failpoints
+
+package failpoints
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEvalRunsRegisteredAction(t *testing.T) {
+	want := errors.New("injected")
+	disable := Enable("test-point", func() error { return want })
+	defer disable()
+
+	if err := Eval("test-point"); err != want {
+		t.Fatalf("expected %v, got %v", want, err)
+	}
+}
+
+func TestEvalUnregisteredIsNoop(t *testing.T) {
+	if err := Eval("no-such-point"); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+}
+
+func TestDisableStopsInjection(t *testing.T) {
+	disable := Enable("test-point-2", func() error { return errors.New("injected") })
+	disable()
+
+	if err := Eval("test-point-2"); err != nil {
+		t.Fatalf("expected nil after Disable, got %v", err)
+	}
+}